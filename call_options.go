@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// CallOption customizes a single API call. Options are attached to the context passed to
+// a client method with WithCallOptions and override the client configuration for that call only.
+type CallOption func(*callOptions)
+
+type callOptions struct {
+	header http.Header
+
+	// apiKey overrides the API key of the client when set.
+	apiKey *string
+
+	// checkpoint is the path of the checkpoint file of a batch, see WithBatchCheckpoint.
+	checkpoint string
+
+	// maxRetries and retryBackoff override the client retry settings when set.
+	maxRetries   *int
+	retryBackoff *time.Duration
+}
+
+type callOptionsKey struct{}
+
+// WithCallOptions returns a copy of ctx carrying opts, in addition to any options already set on ctx.
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	options := callOptionsFromContext(ctx)
+	options.header = options.header.Clone()
+	if options.header == nil {
+		options.header = make(http.Header)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return context.WithValue(ctx, callOptionsKey{}, options)
+}
+
+// WithOrganization sends the OpenAI-Organization header for the call, overriding ClientConfig.OrgID.
+func WithOrganization(id string) CallOption {
+	return func(args *callOptions) {
+		args.header.Set("OpenAI-Organization", id)
+	}
+}
+
+// WithProject sends the OpenAI-Project header for the call.
+func WithProject(id string) CallOption {
+	return func(args *callOptions) {
+		args.header.Set("OpenAI-Project", id)
+	}
+}
+
+// WithAPIKey authenticates the call with key instead of the API key of the client, so that
+// a single client can serve several tenants. The client itself is not modified.
+func WithAPIKey(key string) CallOption {
+	return func(args *callOptions) {
+		args.apiKey = &key
+	}
+}
+
+// WithAccept sends the Accept header for the call instead of the default application/json,
+// for OpenAI-compatible servers that negotiate the response format. Streaming calls always
+// ask for text/event-stream.
+func WithAccept(mediaType string) CallOption {
+	return func(args *callOptions) {
+		args.header.Set("Accept", mediaType)
+	}
+}
+
+// WithBatchCheckpoint makes CreateImages and CreateImagesFromPrompts record every successful
+// generation of the call in the file at path, keyed by the position of the request in the batch
+// and HashImageRequest, and serve the requests already recorded there from the file instead of
+// generating them again. Rerunning a batch that failed or crashed partway with the same
+// checkpoint then only pays for the missing images.
+func WithBatchCheckpoint(path string) CallOption {
+	return func(args *callOptions) {
+		args.checkpoint = path
+	}
+}
+
+// WithRetries sets the number of retries for the call, overriding ClientConfig.MaxRetries.
+// Zero disables retries for the call.
+func WithRetries(maxRetries int) CallOption {
+	return func(args *callOptions) {
+		args.maxRetries = &maxRetries
+	}
+}
+
+// WithRetryBackoff sets the delay before the first retry of the call, overriding ClientConfig.RetryBackoff.
+func WithRetryBackoff(backoff time.Duration) CallOption {
+	return func(args *callOptions) {
+		args.retryBackoff = &backoff
+	}
+}
+
+func callOptionsFromContext(ctx context.Context) callOptions {
+	options, _ := ctx.Value(callOptionsKey{}).(callOptions)
+	return options
+}
+
+// applyCallOptions sets the per-call headers stored in the request context.
+func applyCallOptions(req *http.Request) {
+	options := callOptionsFromContext(req.Context())
+	for key, values := range options.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+}
@@ -0,0 +1,169 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestCallOptionsOrganizationAndProject(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.OrgID = "default-org"
+	})
+	defer teardown()
+
+	var organization, project string
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		organization = r.Header.Get("OpenAI-Organization")
+		project = r.Header.Get("OpenAI-Project")
+		handleImageEndpoint(w, r)
+	})
+	request := openai.ImageRequest{Prompt: "Lorem ipsum"}
+
+	ctx := openai.WithCallOptions(context.Background(),
+		openai.WithOrganization("tenant-org"),
+		openai.WithProject("tenant-project"),
+	)
+	_, err := client.CreateImage(ctx, request)
+	checks.NoError(t, err, "CreateImage error")
+	if organization != "tenant-org" || project != "tenant-project" {
+		t.Errorf("expected per-call headers, got organization %q and project %q", organization, project)
+	}
+
+	_, err = client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error")
+	if organization != "default-org" || project != "" {
+		t.Errorf("expected client defaults, got organization %q and project %q", organization, project)
+	}
+}
+
+func TestWithCallOptionsExtendsParent(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var organization, project string
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		organization = r.Header.Get("OpenAI-Organization")
+		project = r.Header.Get("OpenAI-Project")
+		handleImageEndpoint(w, r)
+	})
+
+	parent := openai.WithCallOptions(context.Background(), openai.WithOrganization("org"))
+	child := openai.WithCallOptions(parent, openai.WithProject("project"))
+
+	_, err := client.CreateImage(parent, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+	if organization != "org" || project != "" {
+		t.Errorf("child options should not leak into the parent context, got %q and %q", organization, project)
+	}
+
+	_, err = client.CreateImage(child, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+	if organization != "org" || project != "project" {
+		t.Errorf("expected inherited and new options, got %q and %q", organization, project)
+	}
+}
+
+func TestCallOptionsRetries(t *testing.T) {
+	retries := 0
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 1
+		config.RetryBackoff = time.Hour
+		config.OnRetry = func(int, error, time.Duration) {
+			retries++
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(3))
+
+	ctx := openai.WithCallOptions(context.Background(),
+		openai.WithRetries(3),
+		openai.WithRetryBackoff(time.Millisecond),
+	)
+	_, err := client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "the call options should allow enough retries to succeed")
+	if retries != 3 {
+		t.Errorf("expected 3 retries, got %d", retries)
+	}
+}
+
+func TestCallOptionsDisableRetries(t *testing.T) {
+	retries := 0
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 3
+		config.RetryBackoff = time.Millisecond
+		config.OnRetry = func(int, error, time.Duration) {
+			retries++
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(1))
+
+	ctx := openai.WithCallOptions(context.Background(), openai.WithRetries(0))
+	_, err := client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.HasError(t, err, "retries should be disabled for the call")
+	if retries != 0 {
+		t.Errorf("expected no retries, got %d", retries)
+	}
+}
+
+func TestCallOptionsAccept(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var accept string
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		handleImageEndpoint(w, r)
+	})
+	request := openai.ImageRequest{Prompt: "Lorem ipsum"}
+
+	_, err := client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error")
+	if accept != "application/json" {
+		t.Errorf("expected the default Accept header, got %q", accept)
+	}
+
+	ctx := openai.WithCallOptions(context.Background(), openai.WithAccept("application/json; charset=utf-8"))
+	_, err = client.CreateImage(ctx, request)
+	checks.NoError(t, err, "CreateImage error")
+	if accept != "application/json; charset=utf-8" {
+		t.Errorf("expected the per-call Accept header, got %q", accept)
+	}
+}
+
+func TestCallOptionsAPIKey(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		handleImageEndpoint(w, r)
+	}))
+	defer ts.Close()
+
+	config := openai.DefaultConfig("client-key")
+	config.BaseURL = ts.URL + "/v1"
+	client := openai.NewClientWithConfig(config)
+	request := openai.ImageRequest{Prompt: "Lorem ipsum"}
+
+	ctx := openai.WithCallOptions(context.Background(), openai.WithAPIKey("tenant-key"))
+	_, err := client.CreateImage(ctx, request)
+	checks.NoError(t, err, "CreateImage error")
+	if authorization != "Bearer tenant-key" {
+		t.Errorf("expected the per-call API key, got %q", authorization)
+	}
+
+	_, err = client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error")
+	if authorization != "Bearer client-key" {
+		t.Errorf("expected the client API key to be unchanged, got %q", authorization)
+	}
+
+	keyless := openai.NewClientWithConfig(openai.DefaultConfig(""))
+	_, err = keyless.CreateImage(openai.WithCallOptions(context.Background(), openai.WithAPIKey("")), request)
+	checks.ErrorIs(t, err, openai.ErrMissingAPIKey, "an empty per-call key should be rejected")
+}
@@ -2,23 +2,49 @@ package openai
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
 
+// ErrMissingAPIKey is returned before sending a request when the client has no API key.
+// Set ClientConfig.AllowEmptyAPIKey for servers without authentication.
+var ErrMissingAPIKey = errors.New("API key is not set")
+
+// ErrTruncatedResponse is returned when a response body ends before its JSON is complete,
+// typically because the connection was cut by a timeout. Unlike malformed JSON, it is a
+// transport problem and the request is worth retrying.
+var ErrTruncatedResponse = errors.New("response body was truncated")
+
+// ErrInvalidBaseURL is returned when ClientConfig.BaseURL is not an absolute http or https URL.
+var ErrInvalidBaseURL = errors.New("invalid base URL")
+
+// ErrOrganizationMismatch is matched by the OrganizationMismatchError returned when the API key
+// does not belong to the organization set with ClientConfig.OrgID or WithOrganization.
+var ErrOrganizationMismatch = errors.New("API key does not belong to the requested organization")
+
+// mismatchedOrganizationCode is the API error code of organization mismatches.
+const mismatchedOrganizationCode = "mismatched_organization"
+
 // Client is OpenAI GPT-3 API client.
 type Client struct {
 	config ClientConfig
 
 	requestBuilder    utils.RequestBuilder
 	createFormBuilder func(io.Writer) utils.FormBuilder
+	clock             clock
+	// customAuth is set when the configured HTTP client may authenticate requests itself.
+	customAuth bool
 }
 
 type Response interface {
@@ -53,13 +79,47 @@ func NewClient(authToken string) *Client {
 
 // NewClientWithConfig creates new OpenAI API client for specified config.
 func NewClientWithConfig(config ClientConfig) *Client {
+	customAuth := hasCustomTransport(config.HTTPClient)
+	config.HTTPClient = withTLSConfig(config.HTTPClient, config.TLSConfig)
 	return &Client{
 		config:         config,
 		requestBuilder: utils.NewRequestBuilder(),
 		createFormBuilder: func(body io.Writer) utils.FormBuilder {
 			return utils.NewFormBuilder(body)
 		},
+		clock:      realClock{},
+		customAuth: customAuth,
+	}
+}
+
+// hasCustomTransport reports whether doer is something other than an *http.Client with the
+// default transport, such as a client whose transport adds its own credentials.
+func hasCustomTransport(doer HTTPDoer) bool {
+	if doer == nil {
+		return false
+	}
+	httpClient, ok := doer.(*http.Client)
+	return !ok || httpClient.Transport != nil
+}
+
+// withTLSConfig returns a copy of doer whose transport uses tlsConfig, when doer is
+// an *http.Client without a custom transport.
+func withTLSConfig(doer HTTPDoer, tlsConfig *tls.Config) HTTPDoer {
+	httpClient, ok := doer.(*http.Client)
+	if tlsConfig == nil || !ok || httpClient.Transport != nil {
+		return doer
+	}
+
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return doer
 	}
+	transport = transport.Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	clientCopy := *httpClient
+	clientCopy.Transport = transport
+	return &clientCopy
 }
 
 // NewOrgClient creates new OpenAI API client for specified Organization ID.
@@ -97,6 +157,13 @@ func withBetaAssistantVersion(version string) requestOption {
 }
 
 func (c *Client) newRequest(ctx context.Context, method, url string, setters ...requestOption) (*http.Request, error) {
+	if c.requiresAPIKey() && c.apiKey(ctx) == "" {
+		return nil, newValidationError(ErrMissingAPIKey)
+	}
+	if err := validateBaseURL(c.config.BaseURL); err != nil {
+		return nil, newValidationError(err)
+	}
+
 	// Default Options
 	args := &requestOptions{
 		body:   nil,
@@ -110,11 +177,15 @@ func (c *Client) newRequest(ctx context.Context, method, url string, setters ...
 		return nil, err
 	}
 	c.setCommonHeaders(req)
+	applyCallOptions(req)
 	return req, nil
 }
 
 func (c *Client) sendRequest(req *http.Request, v Response) error {
-	req.Header.Set("Accept", "application/json")
+	// The Accept header may have been set for the call with WithAccept.
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
 
 	// Check whether Content-Type is already set, Upload Files API requires
 	// Content-Type == multipart/form-data
@@ -123,7 +194,8 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	res, err := c.config.HTTPClient.Do(req)
+	start := c.clock.Now()
+	res, err := c.doRequest(req)
 	if err != nil {
 		return err
 	}
@@ -138,11 +210,42 @@ func (c *Client) sendRequest(req *http.Request, v Response) error {
 		return c.handleErrorResp(res)
 	}
 
-	return decodeResponse(res.Body, v)
+	if c.config.CheckErrorInSuccessBody {
+		err = c.decodeSuccessResponse(res, v)
+	} else {
+		err = decodeResponse(res.Body, v)
+	}
+	if timed, ok := v.(latencyRecorder); ok && err == nil && c.config.MeasureLatency {
+		timed.setLatency(c.clock.Now().Sub(start))
+	}
+	return err
+}
+
+// latencyRecorder is implemented by the responses that report ClientConfig.MeasureLatency timings.
+type latencyRecorder interface {
+	setLatency(latency time.Duration)
+}
+
+// decodeSuccessResponse decodes a successful response into v, unless its body carries an
+// "error" member, which is returned as the error of a failed response would be.
+func (c *Client) decodeSuccessResponse(res *http.Response, v Response) error {
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	var probe struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &probe) == nil && len(probe.Error) != 0 && string(probe.Error) != "null" {
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		return c.handleErrorResp(res)
+	}
+	return decodeResponse(bytes.NewReader(body), v)
 }
 
 func (c *Client) sendRequestRaw(req *http.Request) (response RawResponse, err error) {
-	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body should be closed by outer function
+	resp, err := c.doRequest(req) //nolint:bodyclose // body should be closed by outer function
 	if err != nil {
 		return
 	}
@@ -163,7 +266,7 @@ func sendRequestStream[T streamable](client *Client, req *http.Request) (*stream
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
 
-	resp, err := client.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	resp, err := client.doRequest(req) //nolint:bodyclose // body is closed in stream.Close()
 	if err != nil {
 		return new(streamReader[T]), err
 	}
@@ -181,19 +284,20 @@ func sendRequestStream[T streamable](client *Client, req *http.Request) (*stream
 }
 
 func (c *Client) setCommonHeaders(req *http.Request) {
+	authToken := c.apiKey(req.Context())
 	// https://learn.microsoft.com/en-us/azure/cognitive-services/openai/reference#authentication
 	switch c.config.APIType {
 	case APITypeAzure, APITypeCloudflareAzure:
 		// Azure API Key authentication
-		req.Header.Set(AzureAPIKeyHeader, c.config.authToken)
+		req.Header.Set(AzureAPIKeyHeader, authToken)
 	case APITypeAnthropic:
 		// https://docs.anthropic.com/en/api/versioning
 		req.Header.Set("anthropic-version", c.config.APIVersion)
 	case APITypeOpenAI, APITypeAzureAD:
 		fallthrough
 	default:
-		if c.config.authToken != "" {
-			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.authToken))
+		if authToken != "" {
+			req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
 		}
 	}
 
@@ -202,6 +306,40 @@ func (c *Client) setCommonHeaders(req *http.Request) {
 	}
 }
 
+// validateBaseURL checks that baseURL is an absolute http or https URL, with or without a
+// trailing slash, naming the problem otherwise.
+func validateBaseURL(baseURL string) error {
+	if strings.TrimSpace(baseURL) != baseURL {
+		return fmt.Errorf("%w: %q has leading or trailing whitespace", ErrInvalidBaseURL, baseURL)
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidBaseURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("%w: %q must start with http:// or https://", ErrInvalidBaseURL, baseURL)
+	}
+	if parsed.Host == "" {
+		return fmt.Errorf("%w: %q has no host", ErrInvalidBaseURL, baseURL)
+	}
+	return nil
+}
+
+// apiKey returns the API key of a call, set with WithAPIKey or in the client configuration.
+func (c *Client) apiKey(ctx context.Context) string {
+	if key := callOptionsFromContext(ctx).apiKey; key != nil {
+		return *key
+	}
+	return c.config.authToken
+}
+
+// requiresAPIKey reports whether requests are authenticated with the configured API key. It is
+// false when empty keys are allowed, when the HTTP client may authenticate requests itself and
+// for the Anthropic API.
+func (c *Client) requiresAPIKey() bool {
+	return !c.config.AllowEmptyAPIKey && !c.customAuth && c.config.APIType != APITypeAnthropic
+}
+
 func isFailureStatusCode(resp *http.Response) bool {
 	return resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest
 }
@@ -217,7 +355,11 @@ func decodeResponse(body io.Reader, v any) error {
 	case *audioTextResponse:
 		return decodeString(body, &o.Text)
 	default:
-		return json.NewDecoder(body).Decode(v)
+		err := json.NewDecoder(body).Decode(v)
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("%w: %v", ErrTruncatedResponse, err)
+		}
+		return err
 	}
 }
 
@@ -293,11 +435,24 @@ func (c *Client) baseURLWithAzureDeployment(baseURL, suffix, model string) (newB
 	return baseURL
 }
 
+// handleErrorResp decodes the error of a failed response, with the secrets of the call redacted.
 func (c *Client) handleErrorResp(resp *http.Response) error {
+	ctx := context.Background()
+	if resp.Request != nil {
+		ctx = resp.Request.Context()
+	}
+	return c.redactError(ctx, c.decodeErrorResp(resp))
+}
+
+func (c *Client) decodeErrorResp(resp *http.Response) error {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return fmt.Errorf("error, reading response body: %w", err)
 	}
+	var wait time.Duration
+	if resp.StatusCode == http.StatusTooManyRequests {
+		wait = suggestedWait(resp.Header, true, c.clock.Now())
+	}
 	var errRes ErrorResponse
 	err = json.Unmarshal(body, &errRes)
 	if err != nil || errRes.Error == nil {
@@ -306,6 +461,7 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 			HTTPStatusCode: resp.StatusCode,
 			Err:            err,
 			Body:           body,
+			SuggestedWait:  wait,
 		}
 		if errRes.Error != nil {
 			reqErr.Err = errRes.Error
@@ -315,6 +471,14 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 
 	errRes.Error.HTTPStatus = resp.Status
 	errRes.Error.HTTPStatusCode = resp.StatusCode
+	errRes.Error.SuggestedWait = wait
+	if errRes.Error.Code == mismatchedOrganizationCode {
+		mismatchErr := &OrganizationMismatchError{Err: errRes.Error}
+		if resp.Request != nil {
+			mismatchErr.Organization = resp.Request.Header.Get("OpenAI-Organization")
+		}
+		return mismatchErr
+	}
 	return errRes.Error
 }
 
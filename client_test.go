@@ -3,12 +3,16 @@ package openai //nolint:testpackage // testing private field
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
@@ -586,3 +590,225 @@ func TestClient_baseURLWithAzureDeployment(t *testing.T) {
 		})
 	}
 }
+
+func TestClientTLSConfig(t *testing.T) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: "proxy.internal"}
+	config := DefaultConfig(test.GetTestToken())
+	config.TLSConfig = tlsConfig
+	client := NewClientWithConfig(config)
+
+	httpClient, ok := client.config.HTTPClient.(*http.Client)
+	if !ok {
+		t.Fatalf("expected *http.Client, got %T", client.config.HTTPClient)
+	}
+	transport, ok := httpClient.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("expected *http.Transport, got %T", httpClient.Transport)
+	}
+	if transport.TLSClientConfig != tlsConfig {
+		t.Error("transport does not use the provided TLS config")
+	}
+	if config.HTTPClient.(*http.Client).Transport != nil {
+		t.Error("the original HTTP client should not be modified")
+	}
+}
+
+func TestClientTLSConfigKeepsCustomTransport(t *testing.T) {
+	customTransport := &http.Transport{}
+	config := DefaultConfig(test.GetTestToken())
+	config.HTTPClient = &http.Client{Transport: customTransport}
+	config.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	client := NewClientWithConfig(config)
+
+	if client.config.HTTPClient.(*http.Client).Transport != customTransport {
+		t.Error("a custom transport should be left untouched")
+	}
+}
+
+func TestNewRequestMissingAPIKey(t *testing.T) {
+	client := NewClient("")
+	_, err := client.CreateImage(context.Background(), ImageRequest{Prompt: "Lorem ipsum"})
+	checks.ErrorIs(t, err, ErrMissingAPIKey, "CreateImage should fail locally without an API key")
+}
+
+func TestNewRequestAllowEmptyAPIKey(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("expected no Authorization header, got %q", r.Header.Get("Authorization"))
+		}
+		fmt.Fprintln(w, `{"created":1}`)
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig("")
+	config.BaseURL = ts.URL + "/v1"
+	config.AllowEmptyAPIKey = true
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateImage(context.Background(), ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage should be sent when empty API keys are allowed")
+}
+
+// authTransport authenticates requests itself, as transports from cloud SDKs do.
+type authTransport struct {
+	token string
+}
+
+func (a *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestNewRequestCustomTransportWithoutAPIKey(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprintln(w, `{"created":1}`)
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig("")
+	config.BaseURL = ts.URL + "/v1"
+	config.HTTPClient = &http.Client{Transport: &authTransport{token: "transport-token"}}
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateImage(context.Background(), ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage should be sent when the transport authenticates requests")
+	if authorization != "Bearer transport-token" {
+		t.Errorf("expected the transport credentials, got %q", authorization)
+	}
+}
+
+// fakeClock records the delays it is asked to wait and fires them immediately.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	delays []time.Duration
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.delays = append(f.delays, d)
+	f.now = f.now.Add(d)
+	fired := make(chan time.Time, 1)
+	fired <- f.now
+	return fired
+}
+
+func TestRetryBackoffWithFakeClock(t *testing.T) {
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, `{"error":{"message":"unavailable"}}`)
+	}))
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.MaxRetries = 3
+	config.RetryBackoff = time.Minute
+	client := NewClientWithConfig(config)
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	client.clock = clk
+
+	start := time.Now()
+	_, err := client.CreateImage(context.Background(), ImageRequest{Prompt: "Lorem ipsum"})
+	checks.HasError(t, err, "CreateImage should fail after the retries")
+	if attempts != 4 {
+		t.Errorf("expected 4 attempts, got %d", attempts)
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}
+	if !reflect.DeepEqual(clk.delays, want) {
+		t.Errorf("expected backoff delays %v, got %v", want, clk.delays)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the fake clock to skip the backoff, took %s", elapsed)
+	}
+}
+
+func TestRetryImageMiddlewareWithFakeClock(t *testing.T) {
+	var attempts int
+	failing := func(context.Context, ImageRequest) (ImageResponse, error) {
+		attempts++
+		return ImageResponse{}, &APIError{HTTPStatusCode: http.StatusServiceUnavailable, Message: "unavailable"}
+	}
+	clk := &fakeClock{now: time.Unix(0, 0)}
+	handler := retryImageMiddleware(clk, 4, time.Minute)(failing)
+
+	start := time.Now()
+	_, err := handler(context.Background(), ImageRequest{Prompt: "Lorem ipsum"})
+	checks.HasError(t, err, "the middleware should fail after the retries")
+	if attempts != 4 {
+		t.Errorf("expected 4 attempts, got %d", attempts)
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute}
+	if !reflect.DeepEqual(clk.delays, want) {
+		t.Errorf("expected backoff delays %v, got %v", want, clk.delays)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("expected the fake clock to skip the backoff, took %s", elapsed)
+	}
+}
+
+func TestRetryDelayIsCapped(t *testing.T) {
+	cases := []struct {
+		backoff time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{500 * time.Millisecond, 1, 500 * time.Millisecond},
+		{500 * time.Millisecond, 4, 4 * time.Second},
+		{500 * time.Millisecond, 40, maxRetryDelay},
+		{500 * time.Millisecond, 1000, maxRetryDelay},
+		{time.Hour, 3, time.Hour},
+		{0, 1000, 0},
+	}
+	for _, c := range cases {
+		if got := retryDelay(c.backoff, c.attempt); got != c.want {
+			t.Errorf("retryDelay(%s, %d): expected %s, got %s", c.backoff, c.attempt, c.want, got)
+		}
+	}
+}
+
+func TestNewRequestBaseURLValidation(t *testing.T) {
+	cases := []struct {
+		baseURL string
+		valid   bool
+	}{
+		{"https://api.openai.com/v1", true},
+		{"https://api.openai.com/v1/", true},
+		{"http://localhost:8080/v1", true},
+		{"api.openai.com/v1", false},
+		{"localhost:8080/v1", false},
+		{" https://api.openai.com/v1", false},
+		{"https://api.openai.com/v1\n", false},
+		{"https:///v1", false},
+	}
+	for _, tc := range cases {
+		config := DefaultConfig(test.GetTestToken())
+		config.BaseURL = tc.baseURL
+		client := NewClientWithConfig(config)
+		_, err := client.newRequest(context.Background(), http.MethodGet, client.fullURL("/models"))
+		if tc.valid {
+			checks.NoError(t, err, fmt.Sprintf("%q should be a valid base URL", tc.baseURL))
+			continue
+		}
+		checks.ErrorIs(t, err, ErrInvalidBaseURL, fmt.Sprintf("%q should be rejected", tc.baseURL))
+		if ErrorKindOf(err) != KindValidation {
+			t.Errorf("expected %q to be a validation error, got %v", tc.baseURL, err)
+		}
+	}
+}
@@ -1,8 +1,10 @@
 package openai
 
 import (
+	"crypto/tls"
 	"net/http"
 	"regexp"
+	"time"
 )
 
 const (
@@ -44,8 +46,96 @@ type ClientConfig struct {
 	AssistantVersion     string
 	AzureModelMapperFunc func(model string) string // replace model to azure deployment name func
 	HTTPClient           HTTPDoer
+	// TLSConfig, if set, is used for the transport of the default HTTP client.
+	// It is ignored when HTTPClient is not an *http.Client or already has a Transport.
+	TLSConfig *tls.Config
 
 	EmptyMessagesLimit uint
+
+	// ImageCache, if set, is consulted by CreateImage before calling the API.
+	ImageCache ImageCache
+
+	// ImageBudget, if set, accumulates the token usage of CreateImage calls and
+	// rejects further calls once its limit has been exceeded.
+	ImageBudget *ImageBudget
+
+	// AllowEmptyAPIKey disables the ErrMissingAPIKey check for OpenAI-compatible servers
+	// that do not require authentication. The check is also skipped when HTTPClient is not
+	// an *http.Client or has its own Transport, which may authenticate requests itself.
+	AllowEmptyAPIKey bool
+
+	// StrictOpenAI rejects image requests using fields that only OpenAI-compatible servers
+	// understand, such as ImageRequest.Seed and ImageRequest.ExtraFields, and transparent
+	// backgrounds without an explicit size.
+	StrictOpenAI bool
+
+	// RampBatchConcurrency makes the batch helpers, such as CreateImages and EditDir, start
+	// with one request in flight and ramp up to their concurrency after every success, halving
+	// it after every 429 response, instead of starting every worker at once.
+	RampBatchConcurrency bool
+
+	// CheckErrorInSuccessBody makes the client look for an "error" member in the JSON body of
+	// successful responses, which some gateways send with a 200 status, and return it as an
+	// *APIError instead of an empty response. Leave it off for endpoints whose responses
+	// legitimately contain an "error" member.
+	CheckErrorInSuccessBody bool
+
+	// MaxB64Images, if positive, rejects image requests for more images than this that would be
+	// returned as b64_json, which makes for very large responses, to protect memory-constrained
+	// services. gpt-image-1 always returns b64_json.
+	MaxB64Images int
+
+	// AllowMultiImageStreams lets CreateImageStream request more than one image. The events of
+	// the images then interleave, told apart by ImageStreamEvent.ImageIndex, and not every
+	// server supports it, so such requests are rejected by default.
+	AllowMultiImageStreams bool
+
+	// MinImageDimension, if positive, rejects edit and variation inputs whose width or height
+	// is below it before uploading them, with an error wrapping ErrImageTooSmall. It decodes
+	// the header of every input, so it is off by default.
+	MinImageDimension int
+
+	// MaxMultipartFields, if positive, rejects image edits whose multipart form would have
+	// more fields, counting every uploaded image, for servers that cap the field count.
+	MaxMultipartFields int
+
+	// MaxRetries is the number of times a request is retried after a transport error,
+	// a 429 or a 5xx response. Retries are disabled when it is zero.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, doubled on every further attempt up to
+	// 5 minutes, or RetryBackoff if longer. Defaults to 500ms when MaxRetries is set.
+	RetryBackoff time.Duration
+	// OnRetry, if set, is called before each retry sleep with the 1-based attempt number,
+	// the error that triggered the retry and the delay before the next attempt.
+	OnRetry func(attempt int, err error, delay time.Duration)
+
+	// EndpointTimeouts bounds the calls to an endpoint, keyed by a suffix of its URL path such as
+	// "/images/generations", and the downloads of image URLs the same way, such as ".png". The
+	// longest matching suffix applies; other calls are only bounded by their context and the
+	// HTTP client.
+	EndpointTimeouts map[string]time.Duration
+
+	// DryRun makes the client validate and build requests without sending them: every call
+	// returns an empty successful response and streams end immediately. OnDryRun, if set,
+	// receives every built request, for example to check request construction in CI.
+	DryRun   bool
+	OnDryRun func(request DryRunRequest)
+
+	// MeasureLatency makes image calls report the time from sending the request to the parsed
+	// response in ImageResponse.Latency.
+	MeasureLatency bool
+
+	// DebugHook, if set, is called after every HTTP attempt with its method, URL, headers
+	// with credentials redacted, request size, status code and duration.
+	DebugHook func(info RequestDebugInfo)
+
+	// RedactStrings are values, such as other credentials, that are replaced with [REDACTED]
+	// in the errors returned by the client and the information passed to DebugHook, along
+	// with the API key, which is always redacted.
+	RedactStrings []string
+
+	// Metrics, if set, receives request, error, retry and traffic counters.
+	Metrics Metrics
 }
 
 func DefaultConfig(authToken string) ClientConfig {
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"net/http"
+	"time"
+)
+
+// redactedHeaderValue replaces the value of credential headers passed to DebugHook.
+const redactedHeaderValue = "[REDACTED]"
+
+// credentialHeaders are the headers that may carry an API key, depending on the API type.
+var credentialHeaders = []string{"Authorization", AzureAPIKeyHeader}
+
+// RequestDebugInfo describes a single HTTP attempt made by the client.
+type RequestDebugInfo struct {
+	Method string
+	URL    string
+	// URL and Err have the API key and ClientConfig.RedactStrings redacted, and Header is a copy
+	// of the request headers with credentials redacted.
+	Header http.Header
+	// RequestSize is the request body length in bytes, or -1 if unknown.
+	RequestSize int64
+	// StatusCode is zero when no response was received.
+	StatusCode int
+	Duration   time.Duration
+	Err        error
+}
+
+// sendAttempt sends req once, reporting the attempt to the metrics sink and the debug hook
+// if they are configured. Transport errors are returned with the secrets of the call redacted.
+func (c *Client) sendAttempt(req *http.Request) (*http.Response, error) {
+	if c.config.DebugHook == nil {
+		resp, err := c.config.HTTPClient.Do(req)
+		return c.recordAttempt(req, resp, err), c.redactError(req.Context(), err)
+	}
+
+	start := c.clock.Now()
+	resp, err := c.config.HTTPClient.Do(req)
+	resp = c.recordAttempt(req, resp, err)
+	err = c.redactError(req.Context(), err)
+	info := RequestDebugInfo{
+		Method:      req.Method,
+		URL:         redactSecrets(req.URL.String(), c.secrets(req.Context())),
+		Header:      sanitizeHeader(req.Header),
+		RequestSize: req.ContentLength,
+		Duration:    c.clock.Now().Sub(start),
+		Err:         err,
+	}
+	if req.ContentLength == 0 && req.Body != nil && req.Body != http.NoBody {
+		info.RequestSize = -1
+	}
+	if resp != nil {
+		info.StatusCode = resp.StatusCode
+	}
+	c.config.DebugHook(info)
+	return resp, err
+}
+
+// sanitizeHeader returns a copy of header with credential values redacted.
+func sanitizeHeader(header http.Header) http.Header {
+	sanitized := header.Clone()
+	for _, name := range credentialHeaders {
+		if sanitized.Get(name) != "" {
+			sanitized.Set(name, redactedHeaderValue)
+		}
+	}
+	return sanitized
+}
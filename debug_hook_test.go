@@ -0,0 +1,42 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestDebugHook(t *testing.T) {
+	var infos []openai.RequestDebugInfo
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.DebugHook = func(info openai.RequestDebugInfo) {
+			infos = append(infos, info)
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleImageEndpoint)
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+
+	if len(infos) != 1 {
+		t.Fatalf("expected the hook to fire once, got %d", len(infos))
+	}
+	info := infos[0]
+	if info.Method != http.MethodPost || !strings.HasSuffix(info.URL, "/v1/images/generations") {
+		t.Errorf("unexpected request %s %s", info.Method, info.URL)
+	}
+	if info.StatusCode != http.StatusOK || info.Duration <= 0 || info.RequestSize <= 0 || info.Err != nil {
+		t.Errorf("unexpected attempt details %+v", info)
+	}
+	if auth := info.Header.Get("Authorization"); auth != "[REDACTED]" {
+		t.Errorf("expected the Authorization header to be redacted, got %q", auth)
+	}
+	if contentType := info.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("expected other headers to be kept, got Content-Type %q", contentType)
+	}
+}
@@ -0,0 +1,59 @@
+package openai
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// dryRunBody is the body of the synthetic response of dry-run requests, which decodes
+// into an empty response of any type.
+const dryRunBody = "{}"
+
+// DryRunRequest is a request the client built but did not send because ClientConfig.DryRun is set.
+type DryRunRequest struct {
+	Method string
+	URL    string
+	// Header is a copy of the request headers with credentials redacted.
+	Header http.Header
+	Body   []byte
+}
+
+// dryRun reports req to ClientConfig.OnDryRun and answers it with a synthetic success
+// without sending it. Streaming requests get an empty stream.
+func (c *Client) dryRun(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if c.config.OnDryRun != nil {
+		c.config.OnDryRun(DryRunRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: sanitizeHeader(req.Header),
+			Body:   body,
+		})
+	}
+
+	responseBody := dryRunBody
+	if strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		responseBody = ""
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader([]byte(responseBody))),
+		ContentLength: int64(len(responseBody)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,57 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestDryRun(t *testing.T) {
+	var built []openai.DryRunRequest
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.DryRun = true
+		config.OnDryRun = func(request openai.DryRunRequest) {
+			built = append(built, request)
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(http.ResponseWriter, *http.Request) {
+		t.Error("dry-run requests should not be sent")
+	})
+
+	response, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum", N: 2})
+	checks.NoError(t, err, "CreateImage error")
+	if len(response.Data) != 0 {
+		t.Errorf("expected an empty response, got %+v", response)
+	}
+	if len(built) != 1 {
+		t.Fatalf("expected one built request, got %d", len(built))
+	}
+	request := built[0]
+	if request.Method != http.MethodPost || !strings.HasSuffix(request.URL, "/v1/images/generations") {
+		t.Errorf("unexpected request %s %s", request.Method, request.URL)
+	}
+	if auth := request.Header.Get("Authorization"); auth != "[REDACTED]" {
+		t.Errorf("expected the API key to be redacted, got %q", auth)
+	}
+	var body openai.ImageRequest
+	checks.NoError(t, json.Unmarshal(request.Body, &body), "could not decode the built body")
+	if body.Prompt != "Lorem ipsum" || body.N != 2 {
+		t.Errorf("unexpected built body %s", request.Body)
+	}
+
+	_, err = client.CreateImage(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  openai.CreateImageModelDallE3,
+		N:      2,
+	})
+	checks.ErrorIs(t, err, openai.ErrImageNUnsupported, "dry runs should still validate requests")
+	if len(built) != 1 {
+		t.Errorf("expected invalid requests not to be built, got %d built requests", len(built))
+	}
+}
@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+)
+
+// endpointTimeout returns the timeout configured in ClientConfig.EndpointTimeouts for path,
+// using the longest matching suffix, or zero if none matches.
+func (c *Client) endpointTimeout(path string) time.Duration {
+	var (
+		timeout time.Duration
+		longest = -1
+	)
+	for suffix, d := range c.config.EndpointTimeouts {
+		if len(suffix) > longest && strings.HasSuffix(path, suffix) {
+			timeout, longest = d, len(suffix)
+		}
+	}
+	return timeout
+}
+
+// withEndpointTimeout derives a context bounded by the timeout of path. The returned cancel
+// function is nil when no timeout applies, so that ctx is used as is.
+func (c *Client) withEndpointTimeout(ctx context.Context, path string) (context.Context, context.CancelFunc) {
+	timeout := c.endpointTimeout(path)
+	if timeout <= 0 {
+		return ctx, nil
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// cancelOnClose releases the context of a response once its body is closed, so that the
+// endpoint timeout also covers reading the body.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
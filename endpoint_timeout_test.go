@@ -0,0 +1,53 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestEndpointTimeouts(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.EndpointTimeouts = map[string]time.Duration{
+			"/images/generations": 50 * time.Millisecond,
+			"/images/variations":  time.Minute,
+		}
+	})
+	defer teardown()
+	slow := func(next func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(200 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+			next(w, r)
+		}
+	}
+	server.RegisterHandler("/v1/images/generations", slow(handleImageEndpoint))
+	server.RegisterHandler("/v1/images/edits", slow(handleEditImageEndpoint))
+
+	start := time.Now()
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the generation to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("expected the generation timeout to apply, took %v", elapsed)
+	}
+
+	// Endpoints without a configured timeout fall back to the context and the HTTP client.
+	origin := createImageFile(t, "image.png")
+	defer origin.Close()
+	_, err = client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:  origin,
+		Prompt: "Lorem ipsum",
+		N:      1,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+}
@@ -1,9 +1,28 @@
 package openai
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
+	"time"
+)
+
+// ErrorKind categorizes where a request failed.
+type ErrorKind int
+
+const (
+	// KindUnknown is returned for errors that do not fall into any other category.
+	KindUnknown ErrorKind = iota
+	// KindValidation errors are detected locally before any request is sent.
+	KindValidation
+	// KindTransport errors occur while sending the request or reading the response.
+	KindTransport
+	// KindAPI errors are reported by the server with a failure status code.
+	KindAPI
 )
 
 // APIError provides error information returned by the OpenAI API.
@@ -16,6 +35,9 @@ type APIError struct {
 	HTTPStatus     string      `json:"-"`
 	HTTPStatusCode int         `json:"-"`
 	InnerError     *InnerError `json:"innererror,omitempty"`
+	// SuggestedWait is how long to wait before retrying a rate-limited request, derived
+	// from the Retry-After and x-ratelimit-reset-* headers. It is zero for other errors.
+	SuggestedWait time.Duration `json:"-"`
 }
 
 // InnerError Azure Content filtering. Only valid for Azure OpenAI Service.
@@ -30,6 +52,22 @@ type RequestError struct {
 	HTTPStatusCode int
 	Err            error
 	Body           []byte
+	// SuggestedWait is how long to wait before retrying a rate-limited request.
+	SuggestedWait time.Duration
+}
+
+// ValidationError wraps a request validation failure detected before sending the request.
+type ValidationError struct {
+	Err error
+}
+
+// OrganizationMismatchError is returned when the API rejects a request because the
+// OpenAI-Organization header names an organization the API key does not belong to.
+// It matches ErrOrganizationMismatch with errors.Is and unwraps to the APIError.
+type OrganizationMismatchError struct {
+	// Organization is the organization the request was sent with.
+	Organization string
+	Err          *APIError
 }
 
 type ErrorResponse struct {
@@ -113,3 +151,83 @@ func (e *RequestError) Error() string {
 func (e *RequestError) Unwrap() error {
 	return e.Err
 }
+
+func (e *RequestError) Kind() ErrorKind {
+	return KindAPI
+}
+
+func (e *APIError) Kind() ErrorKind {
+	return KindAPI
+}
+
+func (e *OrganizationMismatchError) Error() string {
+	return fmt.Sprintf("%s: %q: %s", ErrOrganizationMismatch, e.Organization, e.Err)
+}
+
+func (e *OrganizationMismatchError) Unwrap() error {
+	return e.Err
+}
+
+func (e *OrganizationMismatchError) Is(target error) bool {
+	return target == ErrOrganizationMismatch
+}
+
+func (e *OrganizationMismatchError) Kind() ErrorKind {
+	return KindAPI
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ValidationError) Kind() ErrorKind {
+	return KindValidation
+}
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindValidation:
+		return "validation"
+	case KindTransport:
+		return "transport"
+	case KindAPI:
+		return "api"
+	case KindUnknown:
+		fallthrough
+	default:
+		return "unknown"
+	}
+}
+
+// ErrorKindOf returns the category of an error returned by the client.
+func ErrorKindOf(err error) ErrorKind {
+	if err == nil {
+		return KindUnknown
+	}
+
+	var kinded interface{ Kind() ErrorKind }
+	if errors.As(err, &kinded) {
+		return kinded.Kind()
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) ||
+		errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, ErrTruncatedResponse) {
+		return KindTransport
+	}
+	return KindUnknown
+}
+
+// newValidationError wraps err as a ValidationError, returning nil for a nil err.
+func newValidationError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ValidationError{Err: err}
+}
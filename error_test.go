@@ -1,12 +1,15 @@
 package openai_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"reflect"
 	"testing"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 )
 
 func TestAPIErrorUnmarshalJSON(t *testing.T) {
@@ -277,3 +280,119 @@ func TestRequestError(t *testing.T) {
 		t.Fatalf("Empty request error occurred")
 	}
 }
+
+func TestErrorKindOf(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, `{"error":{"message":"invalid prompt","type":"invalid_request_error"}}`)
+	})
+	ctx := context.Background()
+
+	_, err := client.CreateImage(ctx, openai.ImageRequest{Model: openai.CreateImageModelDallE3, N: 2})
+	if kind := openai.ErrorKindOf(err); kind != openai.KindValidation {
+		t.Errorf("expected validation error, got %s: %v", kind, err)
+	}
+
+	_, err = client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if kind := openai.ErrorKindOf(err); kind != openai.KindAPI {
+		t.Errorf("expected API error, got %s: %v", kind, err)
+	}
+
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = "http://127.0.0.1:0/v1"
+	_, err = openai.NewClientWithConfig(config).CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if kind := openai.ErrorKindOf(err); kind != openai.KindTransport {
+		t.Errorf("expected transport error, got %s: %v", kind, err)
+	}
+
+	_, err = openai.NewClient("").CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if kind := openai.ErrorKindOf(err); kind != openai.KindValidation {
+		t.Errorf("expected validation error for a missing API key, got %s: %v", kind, err)
+	}
+
+	if kind := openai.ErrorKindOf(errors.New("other")); kind != openai.KindUnknown {
+		t.Errorf("expected unknown error kind, got %s", kind)
+	}
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	body := `{"created":1700000000,"data":[{"url":"https://example.com/image.png"`
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, body)
+	})
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if !errors.Is(err, openai.ErrTruncatedResponse) {
+		t.Fatalf("expected ErrTruncatedResponse, got %v", err)
+	}
+	if kind := openai.ErrorKindOf(err); kind != openai.KindTransport {
+		t.Errorf("expected a truncated response to be a transport error, got %s", kind)
+	}
+
+	body = `{"created":}`
+	_, err = client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if err == nil || errors.Is(err, openai.ErrTruncatedResponse) {
+		t.Errorf("expected malformed JSON to be reported as such, got %v", err)
+	}
+}
+
+func TestOrganizationMismatch(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.OrgID = "org-wrong"
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"message":"OpenAI-Organization header should match organization for API key",`+
+			`"type":"invalid_request_error","param":null,"code":"mismatched_organization"}}`)
+	})
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if !errors.Is(err, openai.ErrOrganizationMismatch) {
+		t.Fatalf("expected ErrOrganizationMismatch, got %v", err)
+	}
+	var mismatchErr *openai.OrganizationMismatchError
+	if !errors.As(err, &mismatchErr) || mismatchErr.Organization != "org-wrong" {
+		t.Errorf("expected the mismatched organization to be reported, got %v", err)
+	}
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusUnauthorized {
+		t.Errorf("expected the API error to be wrapped, got %v", err)
+	}
+}
+
+func TestCheckErrorInSuccessBody(t *testing.T) {
+	body := `{"error":{"message":"upstream model unavailable","type":"server_error"}}`
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, body)
+	}
+
+	client, server, teardown := setupOpenAITestServer()
+	server.RegisterHandler("/v1/images/generations", handler)
+	response, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	teardown()
+	if err != nil || len(response.Data) != 0 {
+		t.Fatalf("expected an empty response without the option, got %v, %v", response, err)
+	}
+
+	client, server, teardown = setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.CheckErrorInSuccessBody = true
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handler)
+	_, err = client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "upstream model unavailable" || apiErr.HTTPStatusCode != http.StatusOK {
+		t.Fatalf("expected the error object to be returned as an APIError, got %v", err)
+	}
+
+	body = `{"created":1,"error":null,"data":[{"url":"https://example.com/image.png"}]}`
+	response, err = client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if err != nil || len(response.Data) != 1 {
+		t.Errorf("expected a null error to be ignored, got %v, %v", response, err)
+	}
+}
@@ -3,9 +3,24 @@ package openai
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	utils "github.com/sashabaranov/go-openai/internal"
+)
+
+var (
+	ErrInvalidImageEndpoint = errors.New("not an image endpoint")
+	ErrTooManyFormFields    = errors.New("multipart form has too many fields")
 )
 
 // Image sizes defined by the OpenAI API.
@@ -21,6 +36,7 @@ const (
 	// gpt-image-1 supported only.
 	CreateImageSize1536x1024 = "1536x1024" // Landscape
 	CreateImageSize1024x1536 = "1024x1536" // Portrait
+	CreateImageSizeAuto      = "auto"
 )
 
 const (
@@ -43,6 +59,8 @@ const (
 	CreateImageQualityHigh   = "high"
 	CreateImageQualityMedium = "medium"
 	CreateImageQualityLow    = "low"
+	// CreateImageQualityAuto lets gpt-image-1 pick the quality.
+	CreateImageQualityAuto = "auto"
 )
 
 const (
@@ -83,6 +101,37 @@ type ImageRequest struct {
 	Moderation        string `json:"moderation,omitempty"`
 	OutputCompression int    `json:"output_compression,omitempty"`
 	OutputFormat      string `json:"output_format,omitempty"`
+
+	// gpt-image-1 only, see CreateImageStream.
+	Stream        bool `json:"stream,omitempty"`
+	PartialImages int  `json:"partial_images,omitempty"`
+
+	// Seed is only understood by some OpenAI-compatible servers, the OpenAI API ignores it.
+	Seed *int `json:"seed,omitempty"`
+	// ExtraFields are added to the JSON body as is, for parameters of OpenAI-compatible
+	// servers that have no field here. They never override the fields above.
+	ExtraFields map[string]any `json:"-"`
+}
+
+// MarshalJSON encodes the request, merging ExtraFields into the body.
+func (r ImageRequest) MarshalJSON() ([]byte, error) {
+	type imageRequest ImageRequest
+	data, err := json.Marshal(imageRequest(r))
+	if err != nil || len(r.ExtraFields) == 0 {
+		return data, err
+	}
+
+	var body map[string]any
+	err = json.Unmarshal(data, &body)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range r.ExtraFields {
+		if _, ok := body[key]; !ok {
+			body[key] = value
+		}
+	}
+	return json.Marshal(body)
 }
 
 // ImageResponse represents a response structure for image API.
@@ -90,10 +139,98 @@ type ImageResponse struct {
 	Created int64                    `json:"created,omitempty"`
 	Data    []ImageResponseDataInner `json:"data,omitempty"`
 	Usage   ImageResponseUsage       `json:"usage,omitempty"`
+	// Warnings are the non-fatal warnings of the server, such as deprecations or clamped
+	// parameters, from the "warnings" member of the body and the Warning headers.
+	Warnings []string `json:"warnings,omitempty"`
+	// Latency is the wall time from sending the request, retries included, to the parsed
+	// response. It is only measured when ClientConfig.MeasureLatency is set.
+	Latency time.Duration `json:"-"`
 
 	httpHeader
 }
 
+func (r *ImageResponse) setLatency(latency time.Duration) {
+	r.Latency = latency
+}
+
+// SetHeader stores the response headers and collects the warnings they carry.
+func (r *ImageResponse) SetHeader(header http.Header) {
+	r.httpHeader.SetHeader(header)
+	r.Warnings = append(r.Warnings, headerWarnings(header)...)
+}
+
+// headerWarnings returns the texts of the Warning headers, which are either plain text or in
+// the RFC 7234 form `299 - "text"`.
+func headerWarnings(header http.Header) []string {
+	var warnings []string
+	for _, value := range header.Values("Warning") {
+		if start, end := strings.Index(value, `"`), strings.LastIndex(value, `"`); start >= 0 && end > start {
+			value = value[start+1 : end]
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			warnings = append(warnings, value)
+		}
+	}
+	return warnings
+}
+
+// SuggestedWait returns how long to wait before the next request when the response reports
+// an exhausted rate limit, and zero otherwise. Failed calls report the same information in
+// the SuggestedWait field of APIError and RequestError.
+func (r ImageResponse) SuggestedWait() time.Duration {
+	return suggestedWait(r.Header(), false, time.Now())
+}
+
+// UnmarshalJSON decodes an ImageResponse leniently: some OpenAI-compatible servers report the
+// creation time as an RFC3339 string, or under "created_at", instead of a Unix timestamp.
+func (r *ImageResponse) UnmarshalJSON(data []byte) error {
+	type imageResponse ImageResponse
+	aux := struct {
+		*imageResponse
+		Created   json.RawMessage `json:"created"`
+		CreatedAt json.RawMessage `json:"created_at"`
+	}{imageResponse: (*imageResponse)(r)}
+	// Keep the warnings collected from the headers, which are set before the body is decoded.
+	warnings := r.Warnings
+	r.Warnings = nil
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.Warnings = append(warnings, r.Warnings...)
+
+	raw := aux.Created
+	if len(raw) == 0 || string(raw) == "null" {
+		raw = aux.CreatedAt
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		r.Created = 0
+		return nil
+	}
+	created, err := parseCreatedTimestamp(raw)
+	if err != nil {
+		return err
+	}
+	r.Created = created
+	return nil
+}
+
+// parseCreatedTimestamp accepts either a Unix timestamp or an RFC3339 string.
+func parseCreatedTimestamp(raw json.RawMessage) (int64, error) {
+	var unix int64
+	if err := json.Unmarshal(raw, &unix); err == nil {
+		return unix, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return 0, fmt.Errorf("created: expected a Unix timestamp or RFC3339 string, got %s", raw)
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return 0, fmt.Errorf("created: %w", err)
+	}
+	return t.Unix(), nil
+}
+
 // ImageResponseInputTokensDetails represents the token breakdown for input tokens.
 type ImageResponseInputTokensDetails struct {
 	TextTokens  int `json:"text_tokens,omitempty"`
@@ -109,6 +246,9 @@ type ImageResponseUsage struct {
 }
 
 // ImageResponseDataInner represents a response data structure for image API.
+// B64JSON is returned exactly as sent by the server: client methods never decode it, so it can
+// be forwarded as is. Decoding only happens on request, through helpers such as
+// ResolveImageBytes, DownloadAll and SaveImages.
 type ImageResponseDataInner struct {
 	URL           string `json:"url,omitempty"`
 	B64JSON       string `json:"b64_json,omitempty"`
@@ -117,6 +257,27 @@ type ImageResponseDataInner struct {
 
 // CreateImage - API call to create an image. This is the main endpoint of the DALL-E API.
 func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (response ImageResponse, err error) {
+	err = newValidationError(c.validateImageRequest(request))
+	if err != nil {
+		return
+	}
+	request.Size = normalizeRequestSize(request.Size)
+
+	if c.config.ImageBudget != nil {
+		err = c.config.ImageBudget.Check()
+		if err != nil {
+			return
+		}
+	}
+
+	var cacheKey string
+	if c.config.ImageCache != nil {
+		cacheKey = HashImageRequest(request)
+		if cached, ok := c.config.ImageCache.Get(cacheKey); ok {
+			return cached, nil
+		}
+	}
+
 	urlSuffix := "/images/generations"
 	req, err := c.newRequest(
 		ctx,
@@ -129,6 +290,15 @@ func (c *Client) CreateImage(ctx context.Context, request ImageRequest) (respons
 	}
 
 	err = c.sendRequest(req, &response)
+	if err != nil {
+		return
+	}
+	if c.config.ImageBudget != nil {
+		c.config.ImageBudget.Add(response.Usage)
+	}
+	if c.config.ImageCache != nil {
+		c.config.ImageCache.Set(cacheKey, response)
+	}
 	return
 }
 
@@ -143,68 +313,499 @@ type ImageEditRequest struct {
 	ResponseFormat string    `json:"response_format,omitempty"`
 	Quality        string    `json:"quality,omitempty"`
 	User           string    `json:"user,omitempty"`
+
+	// gpt-image-1 only.
+	Background        string `json:"background,omitempty"`
+	Moderation        string `json:"moderation,omitempty"`
+	OutputCompression int    `json:"output_compression,omitempty"`
+	OutputFormat      string `json:"output_format,omitempty"`
+
+	// ImageContentType is the content type of Image. CreateEditImage detects it from the image
+	// bytes when empty, falling back to image/png.
+	ImageContentType string `json:"-"`
+	// ImageLength, if positive, is the length of Image. The image part then carries a
+	// Content-Length header and is cut at that length; an Image shorter than ImageLength
+	// fails with io.ErrUnexpectedEOF. Some strict servers reject parts of unknown length.
+	ImageLength int64 `json:"-"`
+	// ImageFieldName is the name of the multipart field holding Image, defaults to "image".
+	// Some OpenAI-compatible servers expect a different name, such as "file".
+	ImageFieldName string `json:"-"`
+	// ImageProvider, if set, is used instead of Image and called for a fresh reader every
+	// time the request is sent, so that the edit can be retried (see ClientConfig.MaxRetries).
+	// Every reader must yield the same image. Retries also need Mask to be nil or seekable.
+	ImageProvider func() (io.Reader, error) `json:"-"`
+}
+
+// ImageEditRequestFromBytes creates an ImageEditRequest for an in-memory image,
+// detecting the image content type from its bytes. ImageContentType is left empty when
+// the bytes are not a recognized image, so that CreateEditImage falls back to image/png.
+func ImageEditRequestFromBytes(data []byte, prompt string) ImageEditRequest {
+	image, contentType := sniffImageContentType(bytes.NewReader(data))
+	return ImageEditRequest{
+		Image:            image,
+		Prompt:           prompt,
+		ImageContentType: contentType,
+	}
+}
+
+// ImageEditRequestFromReader creates an ImageEditRequest for an image of known length,
+// which the image part advertises to the server, see ImageEditRequest.ImageLength.
+func ImageEditRequestFromReader(r io.Reader, length int64, prompt string) ImageEditRequest {
+	return ImageEditRequest{
+		Image:       r,
+		Prompt:      prompt,
+		ImageLength: length,
+	}
 }
 
 // CreateEditImage - API call to create an image. This is the main endpoint of the DALL-E API.
 func (c *Client) CreateEditImage(ctx context.Context, request ImageEditRequest) (response ImageResponse, err error) {
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
+	err = newValidationError(request.Validate())
+	if err == nil {
+		err = newValidationError(c.checkB64Images(request.Model, request.ResponseFormat, request.N))
+	}
+	if err != nil {
+		return
+	}
+	request.Size = normalizeRequestSize(request.Size)
+	if request.ImageProvider != nil {
+		request.Image, err = request.ImageProvider()
+		if err != nil {
+			return
+		}
+	}
+	request.Image, err = checkImageContent(request.Image)
+	if err == nil {
+		request.Image, err = c.checkMinDimension(request.Image)
+	}
+	if err != nil {
+		err = newValidationError(err)
+		return
+	}
+	if request.ImageContentType == "" {
+		request.Image, request.ImageContentType = sniffImageContentType(request.Image)
+	}
+	var getBody func(contentType string) func() (io.ReadCloser, error)
+	if request.ImageProvider != nil {
+		getBody, err = c.editBodyRebuilder(request)
+		if err != nil {
+			return
+		}
+	}
 
-	// image, filename is not required
-	err = builder.CreateFormFileReaderWithContentType("image", request.Image, "", "image/png")
+	files := []io.Reader{request.Image}
+	if request.Mask != nil {
+		files = append(files, request.Mask)
+	}
+	body, contentType, contentLength, replayBody, err := c.buildMultipartBody(func(builder utils.FormBuilder) error {
+		return writeEditForm(builder, request)
+	}, files...)
+	if err != nil {
+		return
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/edits", withModel(request.Model)),
+		withBody(body),
+		withContentType(contentType),
+	)
 	if err != nil {
+		closeBody(body)
 		return
 	}
+	if contentLength > 0 {
+		req.ContentLength = contentLength
+	}
+	if getBody != nil {
+		req.GetBody = getBody(contentType)
+	} else if replayBody != nil {
+		req.GetBody = replayBody
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// editBodyRebuilder prepares rebuilding the edit form with a fresh image from the request's
+// ImageProvider. It returns nil if the mask cannot be rewound. The returned function is given
+// the content type of the first form, whose boundary the rebuilt forms reuse.
+func (c *Client) editBodyRebuilder(request ImageEditRequest) (func(string) func() (io.ReadCloser, error), error) {
+	var maskOffset int64
+	mask, seekable := request.Mask.(io.Seeker)
+	if request.Mask != nil {
+		if !seekable {
+			return nil, nil
+		}
+		var err error
+		maskOffset, err = mask.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(contentType string) func() (io.ReadCloser, error) {
+		_, params, _ := mime.ParseMediaType(contentType)
+		return func() (io.ReadCloser, error) {
+			image, err := request.ImageProvider()
+			if err != nil {
+				return nil, err
+			}
+			if mask != nil {
+				_, err = mask.Seek(maskOffset, io.SeekStart)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			body := &bytes.Buffer{}
+			builder := c.createFormBuilder(body)
+			if setter, ok := builder.(boundarySetter); ok {
+				err = setter.SetBoundary(params["boundary"])
+				if err != nil {
+					return nil, err
+				}
+			}
+			retry := request
+			retry.Image = image
+			err = writeEditForm(builder, retry)
+			if err != nil {
+				return nil, err
+			}
+			return io.NopCloser(body), nil
+		}
+	}, nil
+}
+
+// BuildEditForm writes the multipart body CreateEditImage would send for request to w and
+// returns its content type, for example to inspect or log the form without sending it. Like
+// CreateEditImage, it detects the image content type from the image bytes when it is not set.
+func BuildEditForm(request ImageEditRequest, w io.Writer) (contentType string, err error) {
+	request.Size = normalizeRequestSize(request.Size)
+	if request.ImageContentType == "" {
+		request.Image, request.ImageContentType = sniffImageContentType(request.Image)
+	}
+	builder := utils.NewFormBuilder(w)
+	err = writeEditForm(builder, request)
+	if err != nil {
+		return "", err
+	}
+	return builder.FormDataContentType(), nil
+}
+
+// EditRequestSize returns the size in bytes of the multipart body CreateEditImage would send
+// for request, without sending it, for example to log upload sizes or check quotas up front.
+// The form is built into a counting writer, so Image and Mask are read in full. Seekable
+// readers are moved back to their position afterwards and the request can still be sent;
+// other readers are consumed. An ImageProvider is called for a reader when Image is nil.
+func EditRequestSize(request ImageEditRequest) (int64, error) {
+	if request.Image == nil && request.ImageProvider != nil {
+		provided, err := request.ImageProvider()
+		if err != nil {
+			return 0, err
+		}
+		request.Image = provided
+	}
+
+	offsets := make(map[io.Seeker]int64)
+	for _, r := range []io.Reader{request.Image, request.Mask} {
+		if seeker, ok := r.(io.Seeker); ok {
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return 0, err
+			}
+			offsets[seeker] = offset
+		}
+	}
+
+	var size byteCounter
+	_, err := BuildEditForm(request, &size)
+	for seeker, offset := range offsets {
+		if _, seekErr := seeker.Seek(offset, io.SeekStart); seekErr != nil && err == nil {
+			err = seekErr
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	return int64(size), nil
+}
+
+// byteCounter is an io.Writer that discards its input and counts the bytes written.
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// defaultImageContentType is the content type of uploaded images whose type is not otherwise known.
+const defaultImageContentType = "image/png"
+
+// writeEditForm writes the multipart fields of an edit request, including the closing boundary.
+func writeEditForm(builder utils.FormBuilder, request ImageEditRequest) error {
+	imageContentType := request.ImageContentType
+	if imageContentType == "" {
+		imageContentType = defaultImageContentType
+	}
+
+	imageFieldName := request.ImageFieldName
+	if imageFieldName == "" {
+		imageFieldName = "image"
+	}
+
+	// image, filename is not required
+	err := writeEditImage(builder, imageFieldName, request.Image, imageContentType, request.ImageLength)
+	if err != nil {
+		return err
+	}
 
 	// mask, it is optional
 	if request.Mask != nil {
 		// mask, filename is not required
 		err = builder.CreateFormFileReader("mask", request.Mask, "")
 		if err != nil {
-			return
+			return err
 		}
 	}
 
 	err = builder.WriteField("prompt", request.Prompt)
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("n", strconv.Itoa(request.N))
 	if err != nil {
-		return
+		return err
 	}
 
 	err = builder.WriteField("size", request.Size)
 	if err != nil {
-		return
+		return err
 	}
 
-	if request.ResponseFormat != "" {
-		err = builder.WriteField("response_format", request.ResponseFormat)
+	compression := ""
+	if request.OutputCompression > 0 {
+		compression = strconv.Itoa(request.OutputCompression)
+	}
+	for _, field := range [][2]string{
+		{"model", request.Model},
+		{"response_format", request.ResponseFormat},
+		{"quality", request.Quality},
+		{"user", request.User},
+		{"background", request.Background},
+		{"moderation", request.Moderation},
+		{"output_format", request.OutputFormat},
+		{"output_compression", compression},
+	} {
+		err = writeOptionalField(builder, field[0], field[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return builder.Close()
+}
+
+// writeEditImage writes the image part of an edit form. With a positive length, the part is
+// cut at length and advertises it when the builder supports it.
+func writeEditImage(
+	builder utils.FormBuilder,
+	fieldName string,
+	image io.Reader,
+	contentType string,
+	length int64,
+) error {
+	if length <= 0 {
+		return builder.CreateFormFileReaderWithContentType(fieldName, image, "", contentType)
+	}
+	type lengthWriter interface {
+		CreateFormFileReaderWithLength(fieldname string, r io.Reader, filename, contentType string, length int64) error
+	}
+	if writer, ok := builder.(lengthWriter); ok {
+		return writer.CreateFormFileReaderWithLength(fieldName, image, "", contentType, length)
+	}
+	return builder.CreateFormFileReaderWithContentType(fieldName, io.LimitReader(image, length), "", contentType)
+}
+
+// writeOptionalField writes a form field unless its value is empty.
+func writeOptionalField(builder utils.FormBuilder, name, value string) error {
+	if value == "" {
+		return nil
+	}
+	return builder.WriteField(name, value)
+}
+
+// buildMultipartBody returns the body written by write and its content type. When every
+// file reader is seekable, the form is first written to a counting writer to learn its length,
+// the readers are rewound and the body is streamed with that length. replayBody then rewinds
+// the readers and streams the form again, so that the request can be retried. Otherwise the
+// form is buffered in memory, contentLength is zero and replayBody is nil.
+func (c *Client) buildMultipartBody(
+	write func(utils.FormBuilder) error,
+	files ...io.Reader,
+) (body io.Reader, contentType string, contentLength int64, replayBody func() (io.ReadCloser, error), err error) {
+	if limit := c.config.MaxMultipartFields; limit > 0 {
+		counter := &fieldCounter{}
+		err = write(counter)
 		if err != nil {
 			return
 		}
+		if counter.fields > limit {
+			err = newValidationError(fmt.Errorf("%w: %d fields, the limit is %d",
+				ErrTooManyFormFields, counter.fields, limit))
+			return
+		}
 	}
 
-	err = builder.Close()
+	seekers, ok := fileSeekers(files)
+	if !ok {
+		buf := &bytes.Buffer{}
+		builder := c.createFormBuilder(buf)
+		err = write(builder)
+		if err != nil {
+			return
+		}
+		return buf, builder.FormDataContentType(), 0, nil, nil
+	}
+
+	offsets := make([]int64, len(seekers))
+	for i, seeker := range seekers {
+		offsets[i], err = seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return
+		}
+	}
+	rewind := func() error {
+		for i, seeker := range seekers {
+			if _, seekErr := seeker.Seek(offsets[i], io.SeekStart); seekErr != nil {
+				return seekErr
+			}
+		}
+		return nil
+	}
+
+	counter := &countingWriter{}
+	err = write(c.createFormBuilder(counter))
 	if err != nil {
 		return
 	}
-
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL("/images/edits", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
+	err = rewind()
 	if err != nil {
 		return
 	}
 
-	err = c.sendRequest(req, &response)
-	return
+	pr, pw := io.Pipe()
+	builder := c.createFormBuilder(pw)
+	contentType = builder.FormDataContentType()
+	done := streamForm(pw, builder, write)
+
+	if _, ok = builder.(boundarySetter); ok {
+		_, params, _ := mime.ParseMediaType(contentType)
+		var mu sync.Mutex
+		replayBody = func() (io.ReadCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			// Stop the previous body and wait for it, so that it no longer reads the files.
+			pr.CloseWithError(errBodyReplaced)
+			<-done
+			if rewindErr := rewind(); rewindErr != nil {
+				return nil, rewindErr
+			}
+
+			pr, pw = io.Pipe()
+			replay := c.createFormBuilder(pw)
+			if boundaryErr := replay.(boundarySetter).SetBoundary(params["boundary"]); boundaryErr != nil {
+				return nil, boundaryErr
+			}
+			done = streamForm(pw, replay, write)
+			return pr, nil
+		}
+	}
+	return pr, contentType, counter.n, replayBody, nil
+}
+
+// errBodyReplaced stops streaming a form body that has been replaced for a retry.
+var errBodyReplaced = errors.New("request body replaced")
+
+// boundarySetter is implemented by form builders whose boundary can be set, such as
+// utils.DefaultFormBuilder, which allows rebuilding a form identically.
+type boundarySetter interface {
+	SetBoundary(boundary string) error
+}
+
+// streamForm writes the form to pw in the background, closing pw with the result. The
+// returned channel is closed once writing has stopped.
+func streamForm(pw *io.PipeWriter, builder utils.FormBuilder, write func(utils.FormBuilder) error) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		pw.CloseWithError(write(builder))
+	}()
+	return done
+}
+
+// fileSeekers returns the readers as seekers, or false if any of them cannot seek.
+func fileSeekers(files []io.Reader) ([]io.Seeker, bool) {
+	seekers := make([]io.Seeker, 0, len(files))
+	for _, file := range files {
+		seeker, ok := file.(io.Seeker)
+		if !ok || file == nil {
+			return nil, false
+		}
+		seekers = append(seekers, seeker)
+	}
+	return seekers, len(seekers) > 0
+}
+
+// closeBody releases a streamed request body that was never sent.
+func closeBody(body io.Reader) {
+	if closer, ok := body.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+// fieldCounter is a utils.FormBuilder that counts the fields written to it without reading any file.
+type fieldCounter struct {
+	fields int
+}
+
+func (f *fieldCounter) CreateFormFile(string, *os.File) error {
+	f.fields++
+	return nil
+}
+
+func (f *fieldCounter) CreateFormFileReader(string, io.Reader, string) error {
+	f.fields++
+	return nil
+}
+
+func (f *fieldCounter) CreateFormFileReaderWithContentType(string, io.Reader, string, string) error {
+	f.fields++
+	return nil
+}
+
+func (f *fieldCounter) WriteField(string, string) error {
+	f.fields++
+	return nil
+}
+
+func (f *fieldCounter) Close() error {
+	return nil
+}
+
+func (f *fieldCounter) FormDataContentType() string {
+	return ""
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
 }
 
 type MultiImageEditRequest struct {
@@ -216,14 +817,23 @@ type MultiImageEditRequest struct {
 	ResponseFormat string      `json:"response_format,omitempty"` // Format of the response (e.g., "b64_json", "url")
 	Quality        string      `json:"quality,omitempty"`         // Quality of the generated images
 	User           string      `json:"user,omitempty"`            // User identifier for tracking
+
+	// FileNames optionally names the uploaded images. When set it must have one entry per image,
+	// and the content type of each image is inferred from the extension of its name.
+	FileNames []string `json:"-"`
 }
 
 func (c *Client) CreateMultiEditImage(ctx context.Context, request MultiImageEditRequest) (response ImageResponse, err error) {
-	if len(request.Images) < 1 {
+	err = newValidationError(request.Validate())
+	if err == nil {
+		err = newValidationError(c.checkB64Images(request.Model, request.ResponseFormat, request.N))
+	}
+	if err != nil {
 		return
 	}
+	request.Size = normalizeRequestSize(request.Size)
 
-	if len(request.Images) == 1 {
+	if len(request.Images) == 1 && len(request.FileNames) == 0 {
 		return c.CreateEditImage(ctx, ImageEditRequest{
 			Image:          request.Images[0],
 			Prompt:         request.Prompt,
@@ -235,57 +845,112 @@ func (c *Client) CreateMultiEditImage(ctx context.Context, request MultiImageEdi
 			User:           request.User,
 		})
 	}
-	body := &bytes.Buffer{}
-	builder := c.createFormBuilder(body)
 
-	// image, filename is not required
-	for _, image := range request.Images {
-		err = builder.CreateFormFileReaderWithContentType("image[]", image, "", "image/png")
+	// Fail fast when the context is already done, before reading any image.
+	err = ctx.Err()
+	if err != nil {
+		return
+	}
+
+	images := make([]io.Reader, len(request.Images))
+	contentTypes := make([]string, len(request.Images))
+	for i, image := range request.Images {
+		images[i], err = checkImageContent(image)
+		if err == nil {
+			images[i], err = c.checkMinDimension(images[i])
+		}
 		if err != nil {
+			err = newValidationError(fmt.Errorf("image %d: %w", i, err))
 			return
 		}
+		if i >= len(request.FileNames) {
+			images[i], contentTypes[i] = sniffImageContentType(images[i])
+		}
 	}
+	request.Images = images
 
-	err = builder.WriteField("prompt", request.Prompt)
+	body, contentType, contentLength, replayBody, err := c.buildMultipartBody(func(builder utils.FormBuilder) error {
+		return writeMultiEditForm(builder, request, contentTypes)
+	}, request.Images...)
 	if err != nil {
 		return
 	}
 
-	err = builder.WriteField("n", strconv.Itoa(request.N))
+	// Buffering the images may take a while, check again before sending.
+	err = ctx.Err()
 	if err != nil {
+		closeBody(body)
 		return
 	}
 
-	err = builder.WriteField("size", request.Size)
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/edits", withModel(request.Model)),
+		withBody(body),
+		withContentType(contentType),
+	)
 	if err != nil {
+		closeBody(body)
 		return
 	}
+	if contentLength > 0 {
+		req.ContentLength = contentLength
+	}
+	if replayBody != nil {
+		req.GetBody = replayBody
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
 
-	if request.ResponseFormat != "" {
-		err = builder.WriteField("response_format", request.ResponseFormat)
+// writeMultiEditForm writes the multipart fields of a multi-image edit request, including the closing boundary.
+// contentTypes holds the detected type of every unnamed image, if known.
+func writeMultiEditForm(builder utils.FormBuilder, request MultiImageEditRequest, contentTypes []string) error {
+	// image, filename is not required
+	for i, image := range request.Images {
+		// Named images get the content type of their extension from the form builder.
+		filename, contentType := "", defaultImageContentType
+		if i < len(request.FileNames) {
+			filename, contentType = request.FileNames[i], ""
+		} else if i < len(contentTypes) && contentTypes[i] != "" {
+			contentType = contentTypes[i]
+		}
+		err := builder.CreateFormFileReaderWithContentType("image[]", image, filename, contentType)
 		if err != nil {
-			return
+			return err
 		}
 	}
 
-	err = builder.Close()
+	err := builder.WriteField("prompt", request.Prompt)
 	if err != nil {
-		return
+		return err
 	}
 
-	req, err := c.newRequest(
-		ctx,
-		http.MethodPost,
-		c.fullURL("/images/edits", withModel(request.Model)),
-		withBody(body),
-		withContentType(builder.FormDataContentType()),
-	)
+	err = builder.WriteField("n", strconv.Itoa(request.N))
 	if err != nil {
-		return
+		return err
 	}
 
-	err = c.sendRequest(req, &response)
-	return
+	err = builder.WriteField("size", request.Size)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range [][2]string{
+		{"model", request.Model},
+		{"response_format", request.ResponseFormat},
+		{"quality", request.Quality},
+		{"user", request.User},
+	} {
+		err = writeOptionalField(builder, field[0], field[1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return builder.Close()
 }
 
 // ImageVariRequest represents the request structure for the image API.
@@ -301,11 +966,33 @@ type ImageVariRequest struct {
 // CreateVariImage - API call to create an image variation. This is the main endpoint of the DALL-E API.
 // Use abbreviations(vari for variation) because ci-lint has a single-line length limit ...
 func (c *Client) CreateVariImage(ctx context.Context, request ImageVariRequest) (response ImageResponse, err error) {
+	err = newValidationError(request.Validate())
+	if err == nil {
+		err = newValidationError(c.checkB64Images(request.Model, request.ResponseFormat, request.N))
+	}
+	if err != nil {
+		return
+	}
+	request.Size = normalizeRequestSize(request.Size)
+	request.Image, err = checkImageContent(request.Image)
+	if err == nil {
+		request.Image, err = c.checkMinDimension(request.Image)
+	}
+	if err != nil {
+		err = newValidationError(err)
+		return
+	}
+	image, imageContentType := sniffImageContentType(request.Image)
+
 	body := &bytes.Buffer{}
 	builder := c.createFormBuilder(body)
 
 	// image, filename is not required
-	err = builder.CreateFormFileReader("image", request.Image, "")
+	if imageContentType != "" {
+		err = builder.CreateFormFileReaderWithContentType("image", image, "", imageContentType)
+	} else {
+		err = builder.CreateFormFileReader("image", image, "")
+	}
 	if err != nil {
 		return
 	}
@@ -344,3 +1031,32 @@ func (c *Client) CreateVariImage(ctx context.Context, request ImageVariRequest)
 	err = c.sendRequest(req, &response)
 	return
 }
+
+// CreateImageRaw sends body as is to an image endpoint such as "/images/generations" and parses
+// the standard image response. It is an escape hatch for reproducing server-side issues with
+// hand-built request bodies; prefer the typed methods otherwise.
+func (c *Client) CreateImageRaw(
+	ctx context.Context,
+	suffix string,
+	body io.Reader,
+	contentType string,
+) (response ImageResponse, err error) {
+	if !strings.HasPrefix(suffix, "/images/") {
+		err = newValidationError(fmt.Errorf("%w: %q", ErrInvalidImageEndpoint, suffix))
+		return
+	}
+
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL(suffix),
+		withBody(body),
+		withContentType(contentType),
+	)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
@@ -1,17 +1,27 @@
 package openai_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
 	"io"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 )
 
@@ -82,15 +92,26 @@ func getImageBody(r *http.Request) (openai.ImageRequest, error) {
 	return image, nil
 }
 
+// createImageFile creates a file in a temporary directory holding a small png image.
+func createImageFile(t *testing.T, name string) *os.File {
+	t.Helper()
+	file, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatalf("create image file error: %v", err)
+	}
+	_, err = file.Write(encodeTestPNG(t, 1, 1))
+	checks.NoError(t, err, "write image file error")
+	_, err = file.Seek(0, io.SeekStart)
+	checks.NoError(t, err, "seek image file error")
+	return file
+}
+
 func TestImageEdit(t *testing.T) {
 	client, server, teardown := setupOpenAITestServer()
 	defer teardown()
 	server.RegisterHandler("/v1/images/edits", handleEditImageEndpoint)
 
-	origin, err := os.Create(filepath.Join(t.TempDir(), "image.png"))
-	if err != nil {
-		t.Fatalf("open origin file error: %v", err)
-	}
+	origin := createImageFile(t, "image.png")
 	defer origin.Close()
 
 	mask, err := os.Create(filepath.Join(t.TempDir(), "mask.png"))
@@ -115,13 +136,10 @@ func TestImageEditWithoutMask(t *testing.T) {
 	defer teardown()
 	server.RegisterHandler("/v1/images/edits", handleEditImageEndpoint)
 
-	origin, err := os.Create(filepath.Join(t.TempDir(), "image.png"))
-	if err != nil {
-		t.Fatalf("open origin file error: %v", err)
-	}
+	origin := createImageFile(t, "image.png")
 	defer origin.Close()
 
-	_, err = client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
 		Image:          origin,
 		Prompt:         "There is a turtle in the pool",
 		N:              3,
@@ -167,13 +185,10 @@ func TestImageVariation(t *testing.T) {
 	defer teardown()
 	server.RegisterHandler("/v1/images/variations", handleVariateImageEndpoint)
 
-	origin, err := os.Create(filepath.Join(t.TempDir(), "image.png"))
-	if err != nil {
-		t.Fatalf("open origin file error: %v", err)
-	}
+	origin := createImageFile(t, "image.png")
 	defer origin.Close()
 
-	_, err = client.CreateVariImage(context.Background(), openai.ImageVariRequest{
+	_, err := client.CreateVariImage(context.Background(), openai.ImageVariRequest{
 		Image:          origin,
 		N:              3,
 		Size:           openai.CreateImageSize1024x1024,
@@ -212,3 +227,685 @@ func handleVariateImageEndpoint(w http.ResponseWriter, r *http.Request) {
 	resBytes, _ = json.Marshal(responses)
 	fmt.Fprintln(w, string(resBytes))
 }
+
+func TestImageEditFromBytes(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	checks.NoError(t, err, "png.Encode error")
+	data := buf.Bytes()
+
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		file, header, formErr := r.FormFile("image")
+		if formErr != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		if contentType := header.Header.Get("Content-Type"); contentType != "image/png" {
+			http.Error(w, "unexpected content type "+contentType, http.StatusBadRequest)
+			return
+		}
+		uploaded, _ := io.ReadAll(file)
+		if !bytes.Equal(uploaded, data) {
+			http.Error(w, "unexpected image content", http.StatusBadRequest)
+			return
+		}
+		handleEditImageEndpoint(w, r)
+	})
+
+	request := openai.ImageEditRequestFromBytes(data, "There is a turtle in the pool")
+	if request.ImageContentType != "image/png" {
+		t.Fatalf("expected detected content type image/png, got %q", request.ImageContentType)
+	}
+	request.Size = openai.CreateImageSize1024x1024
+	_, err = client.CreateEditImage(context.Background(), request)
+	checks.NoError(t, err, "CreateEditImage error")
+}
+
+func TestImageEditFromBytesNotAnImage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var contentType string
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		file, header, formErr := r.FormFile("image")
+		if formErr != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		contentType = header.Header.Get("Content-Type")
+		handleEditImageEndpoint(w, r)
+	})
+
+	request := openai.ImageEditRequestFromBytes([]byte("not an image"), "There is a turtle in the pool")
+	if request.ImageContentType != "" {
+		t.Fatalf("expected no content type for non-image bytes, got %q", request.ImageContentType)
+	}
+	_, err := client.CreateEditImage(context.Background(), request)
+	checks.NoError(t, err, "CreateEditImage error")
+	if contentType != "image/png" {
+		t.Errorf("expected the image/png fallback, got %q", contentType)
+	}
+}
+
+func TestImageEditNPerModel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", handleEditImageEndpoint)
+
+	cases := []struct {
+		model   string
+		n       int
+		wantErr bool
+	}{
+		{openai.CreateImageModelDallE2, 3, false},
+		{openai.CreateImageModelDallE3, 1, false},
+		{openai.CreateImageModelDallE3, 2, true},
+		{openai.CreateImageModelGptImage1, 4, false},
+		{"custom-model", 20, false},
+	}
+	for _, tc := range cases {
+		_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+			Image:  bytes.NewReader([]byte("image")),
+			Prompt: "There is a turtle in the pool",
+			Model:  tc.model,
+			N:      tc.n,
+		})
+		if tc.wantErr {
+			checks.ErrorIs(t, err, openai.ErrImageNUnsupported, tc.model+" should reject n > 1")
+		} else {
+			checks.NoError(t, err, tc.model+" CreateEditImage error")
+		}
+	}
+}
+
+func TestImageVariationRejectsNForDallE3(t *testing.T) {
+	client := openai.NewClient(test.GetTestToken())
+	_, err := client.CreateVariImage(context.Background(), openai.ImageVariRequest{
+		Image: bytes.NewReader([]byte("image")),
+		Model: openai.CreateImageModelDallE3,
+		N:     2,
+	})
+	checks.ErrorIs(t, err, openai.ErrImageNUnsupported, "CreateVariImage should reject n > 1 for dall-e-3")
+}
+
+func TestImageEditSeekableContentLength(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "could not read request", http.StatusInternalServerError)
+			return
+		}
+		if len(r.TransferEncoding) > 0 || r.ContentLength != int64(len(body)) {
+			http.Error(w, fmt.Sprintf("unexpected content length %d for %d bytes", r.ContentLength, len(body)),
+				http.StatusLengthRequired)
+			return
+		}
+		handleEditImageEndpoint(w, r)
+	})
+
+	origin := bytes.NewReader(bytes.Repeat([]byte("i"), 4096))
+	// Only the unread part of a reader is uploaded, so start the mask mid-way.
+	mask := bytes.NewReader([]byte("skipped-mask"))
+	_, _ = mask.Seek(int64(len("skipped-")), io.SeekStart)
+
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:  origin,
+		Mask:   mask,
+		Prompt: "There is a turtle in the pool",
+		N:      1,
+		Size:   openai.CreateImageSize1024x1024,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+}
+
+func TestCreateImageRaw(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			http.Error(w, "unexpected content type", http.StatusBadRequest)
+			return
+		}
+		handleImageEndpoint(w, r)
+	})
+
+	body := `{"prompt":"Lorem ipsum","n":2,"response_format":"url","unknown_field":true}`
+	response, err := client.CreateImageRaw(context.Background(), "/images/generations",
+		bytes.NewBufferString(body), "application/json")
+	checks.NoError(t, err, "CreateImageRaw error")
+	if len(response.Data) != 2 {
+		t.Errorf("expected 2 images, got %d", len(response.Data))
+	}
+
+	_, err = client.CreateImageRaw(context.Background(), "/chat/completions",
+		bytes.NewBufferString(body), "application/json")
+	checks.ErrorIs(t, err, openai.ErrInvalidImageEndpoint, "CreateImageRaw should only target image endpoints")
+}
+
+func TestMultiImageEditCanceledContext(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	called := false
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		handleEditImageEndpoint(w, r)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.CreateMultiEditImage(ctx, openai.MultiImageEditRequest{
+		Images: []io.Reader{bytes.NewReader([]byte("first")), bytes.NewReader([]byte("second"))},
+		Prompt: "There is a turtle in the pool",
+		Model:  openai.CreateImageModelGptImage1,
+		N:      1,
+	})
+	checks.ErrorIs(t, err, context.Canceled, "CreateMultiEditImage should return the context error")
+	if called {
+		t.Error("no request should be sent once the context is canceled")
+	}
+}
+
+func TestMultiImageEditStreamsSeekableImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseMultipartForm(1 << 20)
+		if err != nil || len(r.MultipartForm.File["image[]"]) != 2 || r.ContentLength <= 0 {
+			http.Error(w, "unexpected multi-image edit request", http.StatusBadRequest)
+			return
+		}
+		handleEditImageEndpoint(w, r)
+	})
+
+	_, err := client.CreateMultiEditImage(context.Background(), openai.MultiImageEditRequest{
+		Images: []io.Reader{bytes.NewReader([]byte("first")), bytes.NewReader([]byte("second"))},
+		Prompt: "There is a turtle in the pool",
+		Model:  openai.CreateImageModelGptImage1,
+		N:      1,
+	})
+	checks.NoError(t, err, "CreateMultiEditImage error")
+}
+
+func TestImageResponseCreatedFormats(t *testing.T) {
+	cases := map[string]string{
+		"unix":              `{"created":1700000000,"data":[{"url":"u"}]}`,
+		"rfc3339":           `{"created":"2023-11-14T22:13:20Z","data":[{"url":"u"}]}`,
+		"created_at":        `{"created_at":"2023-11-14T23:13:20+01:00","data":[{"url":"u"}]}`,
+		"created_at as int": `{"created_at":1700000000,"data":[{"url":"u"}]}`,
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			var response openai.ImageResponse
+			err := json.Unmarshal([]byte(body), &response)
+			checks.NoError(t, err, "Unmarshal error")
+			if response.Created != 1700000000 {
+				t.Errorf("expected created 1700000000, got %d", response.Created)
+			}
+			if len(response.Data) != 1 || response.Data[0].URL != "u" {
+				t.Errorf("expected the other fields to be decoded, got %+v", response.Data)
+			}
+		})
+	}
+
+	var response openai.ImageResponse
+	err := json.Unmarshal([]byte(`{"created":"yesterday"}`), &response)
+	checks.HasError(t, err, "invalid timestamps should be rejected")
+}
+
+func TestBuildEditForm(t *testing.T) {
+	data := []byte("fake image data")
+	request := openai.ImageEditRequest{
+		Image:          bytes.NewReader(data),
+		Mask:           bytes.NewReader([]byte("fake mask")),
+		Prompt:         "There is a turtle in the pool",
+		N:              2,
+		Size:           openai.CreateImageSize512x512,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+	}
+
+	var buf bytes.Buffer
+	contentType, err := openai.BuildEditForm(request, &buf)
+	checks.NoError(t, err, "BuildEditForm error")
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	checks.NoError(t, err, "ParseMediaType error")
+	if mediaType != "multipart/form-data" {
+		t.Fatalf("unexpected media type %q", mediaType)
+	}
+
+	parts := make(map[string]string)
+	reader := multipart.NewReader(&buf, params["boundary"])
+	for {
+		part, partErr := reader.NextPart()
+		if errors.Is(partErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, partErr, "NextPart error")
+		value, readErr := io.ReadAll(part)
+		checks.NoError(t, readErr, "could not read part")
+		parts[part.FormName()] = string(value)
+	}
+
+	expected := map[string]string{
+		"image":           string(data),
+		"mask":            "fake mask",
+		"prompt":          request.Prompt,
+		"n":               "2",
+		"size":            openai.CreateImageSize512x512,
+		"response_format": openai.CreateImageResponseFormatB64JSON,
+	}
+	for name, value := range expected {
+		if parts[name] != value {
+			t.Errorf("field %q: expected %q, got %q", name, value, parts[name])
+		}
+	}
+	if len(parts) != len(expected) {
+		t.Errorf("unexpected fields %v", parts)
+	}
+}
+
+func TestImageEditCustomImageFieldName(t *testing.T) {
+	var buf bytes.Buffer
+	contentType, err := openai.BuildEditForm(openai.ImageEditRequest{
+		Image:          bytes.NewReader([]byte("fake image data")),
+		Prompt:         "There is a turtle in the pool",
+		ImageFieldName: "file",
+	}, &buf)
+	checks.NoError(t, err, "BuildEditForm error")
+
+	_, params, err := mime.ParseMediaType(contentType)
+	checks.NoError(t, err, "ParseMediaType error")
+	part, err := multipart.NewReader(&buf, params["boundary"]).NextPart()
+	checks.NoError(t, err, "NextPart error")
+	if disposition := part.Header.Get("Content-Disposition"); !strings.Contains(disposition, `name="file"`) {
+		t.Errorf("expected the image part to be named file, got %q", disposition)
+	}
+}
+
+func TestImageEditEmptyImage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", handleEditImageEndpoint)
+	ctx := context.Background()
+
+	for name, image := range map[string]io.Reader{
+		"seekable":     bytes.NewReader(nil),
+		"not seekable": io.MultiReader(),
+	} {
+		_, err := client.CreateEditImage(ctx, openai.ImageEditRequest{Image: image, Prompt: "cat"})
+		checks.ErrorIs(t, err, openai.ErrEmptyImage, name+" empty images should be rejected")
+	}
+
+	_, err := client.CreateMultiEditImage(ctx, openai.MultiImageEditRequest{
+		Images: []io.Reader{bytes.NewReader([]byte("first")), bytes.NewReader(nil)},
+		Prompt: "cat",
+	})
+	checks.ErrorIs(t, err, openai.ErrEmptyImage, "empty images should be rejected in multi-image edits")
+
+	_, err = client.CreateVariImage(ctx, openai.ImageVariRequest{Image: io.MultiReader()})
+	checks.ErrorIs(t, err, openai.ErrEmptyImage, "empty images should be rejected in variations")
+}
+
+func TestImageEditPeekedImageIsComplete(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	data := []byte("fake image data")
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		if uploaded, _ := io.ReadAll(file); !bytes.Equal(uploaded, data) {
+			http.Error(w, "unexpected image content "+string(uploaded), http.StatusBadRequest)
+			return
+		}
+		handleEditImageEndpoint(w, r)
+	})
+
+	// A reader that cannot seek is peeked, the peeked byte must still be uploaded.
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:  io.MultiReader(bytes.NewReader(data)),
+		Prompt: "cat",
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+}
+
+func TestCreateImageReturnsRawB64(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	// Not valid base64: any decoding on the way would fail or alter it.
+	const raw = "not base64, forwarded as is!"
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"data":[{"b64_json":%q}]}`, raw)
+	})
+
+	response, err := client.CreateImage(context.Background(), openai.ImageRequest{
+		Prompt:         "Lorem ipsum",
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+	})
+	checks.NoError(t, err, "CreateImage error")
+	if response.Data[0].B64JSON != raw {
+		t.Errorf("expected the raw b64_json value, got %q", response.Data[0].B64JSON)
+	}
+}
+
+func TestMultiImageEditMaxMultipartFields(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		// 3 images, prompt, n and size.
+		config.MaxMultipartFields = 6
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", handleEditImageEndpoint)
+
+	request := openai.MultiImageEditRequest{
+		Images: []io.Reader{
+			bytes.NewReader([]byte("first")),
+			bytes.NewReader([]byte("second")),
+			bytes.NewReader([]byte("third")),
+		},
+		Prompt: "There is a turtle in the pool",
+	}
+	_, err := client.CreateMultiEditImage(context.Background(), request)
+	checks.NoError(t, err, "a form at the limit should be sent")
+
+	request.Images = append(request.Images, bytes.NewReader([]byte("fourth")))
+	for _, image := range request.Images {
+		_, _ = image.(io.Seeker).Seek(0, io.SeekStart)
+	}
+	_, err = client.CreateMultiEditImage(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrTooManyFormFields, "a form over the limit should be rejected")
+}
+
+func TestEditRequestSize(t *testing.T) {
+	imageReader := bytes.NewReader([]byte("fake image data"))
+	request := openai.ImageEditRequest{
+		Image:  imageReader,
+		Mask:   bytes.NewReader([]byte("fake mask")),
+		Prompt: "There is a turtle in the pool",
+		N:      2,
+	}
+
+	size, err := openai.EditRequestSize(request)
+	checks.NoError(t, err, "EditRequestSize error")
+	if imageReader.Len() != len("fake image data") {
+		t.Fatalf("expected the image to be rewound, %d bytes left", imageReader.Len())
+	}
+
+	var buf bytes.Buffer
+	_, err = openai.BuildEditForm(request, &buf)
+	checks.NoError(t, err, "BuildEditForm error")
+	if size != int64(buf.Len()) {
+		t.Errorf("expected size %d to match the built body, got %d", buf.Len(), size)
+	}
+}
+
+func TestEditRequestSizeMatchesSentJPEG(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var sent []byte
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(sent))
+		handleEditImageEndpoint(w, r)
+	})
+
+	var jpegData bytes.Buffer
+	checks.NoError(t, jpeg.Encode(&jpegData, image.NewRGBA(image.Rect(0, 0, 2, 2)), nil), "jpeg.Encode error")
+	imageReader := bytes.NewReader(jpegData.Bytes())
+	request := openai.ImageEditRequest{
+		Image:  imageReader,
+		Prompt: "There is a turtle in the pool",
+		N:      1,
+	}
+
+	size, err := openai.EditRequestSize(request)
+	checks.NoError(t, err, "EditRequestSize error")
+	_, err = client.CreateEditImage(context.Background(), request)
+	checks.NoError(t, err, "CreateEditImage error")
+	if size != int64(len(sent)) {
+		t.Errorf("expected size %d to match the sent body, got %d", len(sent), size)
+	}
+
+	_, err = imageReader.Seek(0, io.SeekStart)
+	checks.NoError(t, err, "seek image error")
+	var buf bytes.Buffer
+	contentType, err := openai.BuildEditForm(request, &buf)
+	checks.NoError(t, err, "BuildEditForm error")
+	_, params, err := mime.ParseMediaType(contentType)
+	checks.NoError(t, err, "ParseMediaType error")
+	part, err := multipart.NewReader(&buf, params["boundary"]).NextPart()
+	checks.NoError(t, err, "NextPart error")
+	if got := part.Header.Get("Content-Type"); got != "image/jpeg" {
+		t.Errorf("expected the image part to be image/jpeg, got %q", got)
+	}
+}
+
+func TestImageResponseWarnings(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Add("Warning", `299 - "dall-e-2 is deprecated"`)
+		fmt.Fprint(w, `{"created":1700000000,"data":[{"url":"u"}],"warnings":["n was clamped to 1"]}`)
+	})
+
+	res, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum", N: 2})
+	checks.NoError(t, err, "warnings should not fail the call")
+	want := []string{"dall-e-2 is deprecated", "n was clamped to 1"}
+	if !reflect.DeepEqual(res.Warnings, want) {
+		t.Errorf("expected warnings %q, got %q", want, res.Warnings)
+	}
+}
+
+func TestImageResponseLatency(t *testing.T) {
+	for _, measure := range []bool{true, false} {
+		client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+			config.MeasureLatency = measure
+		})
+		server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(50 * time.Millisecond)
+			handleImageEndpoint(w, r)
+		})
+
+		res, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum", N: 1})
+		teardown()
+		checks.NoError(t, err, "CreateImage error")
+		if !measure {
+			if res.Latency != 0 {
+				t.Errorf("expected no latency without MeasureLatency, got %v", res.Latency)
+			}
+			continue
+		}
+		if res.Latency < 50*time.Millisecond || res.Latency > 5*time.Second {
+			t.Errorf("expected a latency of about 50ms, got %v", res.Latency)
+		}
+	}
+}
+
+func TestImageEditGptImage1Fields(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var form map[string][]string
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, "could not parse form", http.StatusBadRequest)
+			return
+		}
+		form = r.MultipartForm.Value
+		handleEditImageEndpoint(w, r)
+	})
+
+	origin := createImageFile(t, "image.png")
+	defer origin.Close()
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:             origin,
+		Prompt:            "Remove the background",
+		Model:             openai.CreateImageModelGptImage1,
+		N:                 1,
+		Background:        openai.CreateImageBackgroundTransparent,
+		Moderation:        openai.CreateImageModerationLow,
+		OutputFormat:      openai.CreateImageOutputFormatWEBP,
+		OutputCompression: 80,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+
+	expected := map[string]string{
+		"background":         openai.CreateImageBackgroundTransparent,
+		"moderation":         openai.CreateImageModerationLow,
+		"output_format":      openai.CreateImageOutputFormatWEBP,
+		"output_compression": "80",
+	}
+	for name, want := range expected {
+		if got := form[name]; len(got) != 1 || got[0] != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+
+	_, err = origin.Seek(0, io.SeekStart)
+	checks.NoError(t, err, "seek image file error")
+	_, err = client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:  origin,
+		Prompt: "Remove the background",
+		N:      1,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+	for name := range expected {
+		if _, ok := form[name]; ok {
+			t.Errorf("%s: expected unset fields to be left out of the form", name)
+		}
+	}
+}
+
+func TestImageEditQuality(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var form map[string][]string
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, "could not parse form", http.StatusBadRequest)
+			return
+		}
+		form = r.MultipartForm.Value
+		handleEditImageEndpoint(w, r)
+	})
+
+	origin := createImageFile(t, "image.png")
+	defer origin.Close()
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:   origin,
+		Prompt:  "Add a hat",
+		Model:   openai.CreateImageModelGptImage1,
+		N:       1,
+		Quality: openai.CreateImageQualityHigh,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+	if got := form["quality"]; len(got) != 1 || got[0] != openai.CreateImageQualityHigh {
+		t.Errorf("quality: expected %q, got %q", openai.CreateImageQualityHigh, got)
+	}
+
+	_, err = origin.Seek(0, io.SeekStart)
+	checks.NoError(t, err, "seek image file error")
+	_, err = client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:  origin,
+		Prompt: "Add a hat",
+		N:      1,
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+	if _, ok := form["quality"]; ok {
+		t.Error("quality: expected an unset quality to be left out of the form")
+	}
+}
+
+func TestImageEditImageLength(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var (
+		length string
+		image  []byte
+	)
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, "could not read form", http.StatusBadRequest)
+			return
+		}
+		part, err := reader.NextPart()
+		if err != nil {
+			http.Error(w, "could not read image part", http.StatusBadRequest)
+			return
+		}
+		length = part.Header.Get("Content-Length")
+		image, _ = io.ReadAll(part)
+		handleEditImageEndpoint(w, r)
+	})
+
+	data := []byte("fake image data and trailing bytes")
+	// A reader that cannot seek, so that its length is unknown to the client.
+	request := openai.ImageEditRequestFromReader(io.MultiReader(bytes.NewReader(data)), 15, "Add a hat")
+	request.N = 1
+	_, err := client.CreateEditImage(context.Background(), request)
+	checks.NoError(t, err, "CreateEditImage error")
+	if length != "15" {
+		t.Errorf("expected the image part to carry Content-Length 15, got %q", length)
+	}
+	if string(image) != "fake image data" {
+		t.Errorf("expected the image to be cut at its length, got %q", image)
+	}
+
+	request = openai.ImageEditRequestFromReader(bytes.NewReader(data), int64(len(data)+1), "Add a hat")
+	request.N = 1
+	_, err = client.CreateEditImage(context.Background(), request)
+	checks.ErrorIs(t, err, io.ErrUnexpectedEOF, "CreateEditImage should fail on an image shorter than its length")
+}
+
+func TestImageEditAndVariationNormalizeSize(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var size string
+	recordSize := func(w http.ResponseWriter, r *http.Request) {
+		size = r.FormValue("size")
+		handleEditImageEndpoint(w, r)
+	}
+	server.RegisterHandler("/v1/images/edits", recordSize)
+	server.RegisterHandler("/v1/images/variations", recordSize)
+	ctx := context.Background()
+
+	_, err := client.CreateEditImage(ctx, openai.ImageEditRequest{
+		Image:  bytes.NewReader([]byte("fake image data")),
+		Prompt: "There is a turtle in the pool",
+		Size:   "1024 X 1024",
+	})
+	checks.NoError(t, err, "CreateEditImage error")
+	if size != openai.CreateImageSize1024x1024 {
+		t.Errorf("edit: expected size %q, got %q", openai.CreateImageSize1024x1024, size)
+	}
+
+	size = ""
+	_, err = client.CreateMultiEditImage(ctx, openai.MultiImageEditRequest{
+		Images: []io.Reader{bytes.NewReader([]byte("first image")), bytes.NewReader([]byte("second image"))},
+		Prompt: "There is a turtle in the pool",
+		Model:  openai.CreateImageModelGptImage1,
+		Size:   "1024 X 1024",
+	})
+	checks.NoError(t, err, "CreateMultiEditImage error")
+	if size != openai.CreateImageSize1024x1024 {
+		t.Errorf("multi-image edit: expected size %q, got %q", openai.CreateImageSize1024x1024, size)
+	}
+
+	size = ""
+	_, err = client.CreateVariImage(ctx, openai.ImageVariRequest{
+		Image: bytes.NewReader([]byte("fake image data")),
+		Size:  "1024 X 1024",
+	})
+	checks.NoError(t, err, "CreateVariImage error")
+	if size != openai.CreateImageSize1024x1024 {
+		t.Errorf("variation: expected size %q, got %q", openai.CreateImageSize1024x1024, size)
+	}
+}
@@ -0,0 +1,369 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrEmptyImagePrompt = errors.New("image prompt cannot be empty")
+
+// ImageBatchResult is the outcome of a single generation within a batch.
+type ImageBatchResult struct {
+	Response ImageResponse
+	Err      error
+}
+
+// ImageBatchResults are the results of a batch, aligned by index with its requests.
+type ImageBatchResults []ImageBatchResult
+
+// BatchReport summarizes the outcome of a batch.
+type BatchReport struct {
+	// Images is the number of images returned by the successful generations.
+	Images int
+	// Succeeded and Failed count the generations of the batch by outcome.
+	Succeeded int
+	Failed    int
+	// Usage is the total token usage of the successful generations.
+	Usage ImageResponseUsage
+	// EstimatedCost is the cost of Usage in US dollars, see ImageResponseUsage.EstimateCost.
+	EstimatedCost float64
+	// Errors counts the failures by API error code, or by ErrorKind for errors without a code.
+	Errors map[string]int
+}
+
+// Report aggregates the results into a BatchReport.
+func (r ImageBatchResults) Report() BatchReport {
+	report := BatchReport{Errors: make(map[string]int)}
+	for _, result := range r {
+		if result.Err != nil {
+			report.Failed++
+			report.Errors[batchErrorKey(result.Err)]++
+			continue
+		}
+		report.Succeeded++
+		report.Images += len(result.Response.Data)
+		report.Usage = report.Usage.plus(result.Response.Usage)
+	}
+	report.EstimatedCost = report.Usage.EstimateCost()
+	return report
+}
+
+// batchErrorKey returns the key of err in BatchReport.Errors.
+func batchErrorKey(err error) string {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code != nil && apiErr.Code != "" {
+		return fmt.Sprint(apiErr.Code)
+	}
+	return ErrorKindOf(err).String()
+}
+
+// CreateImagesFromPrompts generates one image request per prompt, using base for all other
+// parameters. At most concurrency requests are in flight at once. The results are aligned
+// by index with prompts; a failed generation is reported in its result's Err. When the client
+// has an ImageBudget, prompts that would start after the budget is exceeded are not sent.
+func (c *Client) CreateImagesFromPrompts(
+	ctx context.Context,
+	prompts []string,
+	base ImageRequest,
+	concurrency int,
+) (ImageBatchResults, error) {
+	requests := make([]ImageRequest, len(prompts))
+	for i, prompt := range prompts {
+		requests[i] = base
+		requests[i].Prompt = prompt
+	}
+	return c.CreateImages(ctx, requests, concurrency)
+}
+
+// CreateImages generates every request, each with its own model and parameters, running at
+// most concurrency requests at once. All requests are validated before any is sent. The
+// results are aligned by index with requests and budgets apply as in CreateImagesFromPrompts.
+// Use ImageBatchResults.Report to summarize them. With WithBatchCheckpoint, the requests
+// completed by an earlier run are not sent again; an error recording a generation is
+// returned along with the results.
+func (c *Client) CreateImages(
+	ctx context.Context,
+	requests []ImageRequest,
+	concurrency int,
+) (results ImageBatchResults, err error) {
+	for i, request := range requests {
+		if strings.TrimSpace(request.Prompt) == "" {
+			return nil, newValidationError(fmt.Errorf("prompt %d: %w", i, ErrEmptyImagePrompt))
+		}
+		if validateErr := request.Validate(); validateErr != nil {
+			return nil, newValidationError(fmt.Errorf("request %d: %w", i, validateErr))
+		}
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	var checkpoint *batchCheckpoint
+	if path := callOptionsFromContext(ctx).checkpoint; path != "" {
+		checkpoint, err = openBatchCheckpoint(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening checkpoint: %w", err)
+		}
+		defer func() {
+			if closeErr := checkpoint.close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	var (
+		mu        sync.Mutex
+		recordErr error
+	)
+	results = make(ImageBatchResults, len(requests))
+	limiter := c.newBatchLimiter(concurrency)
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		var key string
+		if checkpoint != nil {
+			key = checkpointKey(i, request)
+			if response, ok := checkpoint.lookup(key); ok {
+				results[i] = ImageBatchResult{Response: response}
+				continue
+			}
+		}
+
+		limiter.acquire()
+		if c.config.ImageBudget != nil {
+			// Stop launching generations once the budget is spent by the ones already done.
+			if budgetErr := c.config.ImageBudget.Check(); budgetErr != nil {
+				limiter.release(nil)
+				results[i] = ImageBatchResult{Err: budgetErr}
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(i int, request ImageRequest, key string) {
+			defer wg.Done()
+			response, createErr := c.CreateImage(ctx, request)
+			limiter.release(createErr)
+			results[i] = ImageBatchResult{Response: response, Err: createErr}
+			if checkpoint == nil || createErr != nil {
+				return
+			}
+			if writeErr := checkpoint.record(key, response); writeErr != nil {
+				mu.Lock()
+				defer mu.Unlock()
+				if recordErr == nil {
+					recordErr = fmt.Errorf("recording checkpoint: %w", writeErr)
+				}
+			}
+		}(i, request, key)
+	}
+	wg.Wait()
+
+	return results, recordErr
+}
+
+// EditDir edits every image file in dir with the same request parameters, running at most
+// concurrency edits at once, and returns the responses keyed by file name. Files whose
+// content is not an image are skipped. If any edit fails, the successful responses are
+// returned along with the first error.
+func (c *Client) EditDir(
+	ctx context.Context,
+	dir string,
+	request ImageEditRequest,
+	concurrency int,
+) (map[string]ImageResponse, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	// The mask is shared by every edit, so it has to be readable once per request.
+	var mask []byte
+	if request.Mask != nil {
+		mask, err = io.ReadAll(request.Mask)
+		if err != nil {
+			return nil, fmt.Errorf("reading mask: %w", err)
+		}
+	}
+
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// Read every file up front so a read error cannot leave edits running in the background.
+	files := make(map[string][]byte)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		data, readErr := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if readErr != nil {
+			return nil, readErr
+		}
+		if strings.HasPrefix(http.DetectContentType(data), "image/") {
+			files[entry.Name()] = data
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		results  = make(map[string]ImageResponse, len(files))
+		firstErr error
+	)
+	limiter := c.newBatchLimiter(concurrency)
+	var wg sync.WaitGroup
+	for name, data := range files {
+		fileRequest := request
+		fileRequest.Image = bytes.NewReader(data)
+		fileRequest.ImageContentType = http.DetectContentType(data)
+		// The image of every edit is its file, whatever the request was set up with.
+		fileRequest.ImageProvider = nil
+		fileRequest.ImageLength = 0
+		if mask != nil {
+			fileRequest.Mask = bytes.NewReader(mask)
+		}
+
+		wg.Add(1)
+		limiter.acquire()
+		go func(name string, request ImageEditRequest) {
+			defer wg.Done()
+			response, editErr := c.CreateEditImage(ctx, request)
+			limiter.release(editErr)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if editErr != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("%s: %w", name, editErr)
+				}
+				return
+			}
+			results[name] = response
+		}(name, fileRequest)
+	}
+	wg.Wait()
+
+	return results, firstErr
+}
+
+// batchLimiter bounds the number of requests a batch helper has in flight. With
+// ClientConfig.RampBatchConcurrency the limit starts at one, grows by one after every
+// success up to the batch concurrency and is halved after every rate limited request.
+// Otherwise the limit is the batch concurrency.
+type batchLimiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	ramp   bool
+	limit  int
+	max    int
+	active int
+}
+
+func (c *Client) newBatchLimiter(concurrency int) *batchLimiter {
+	l := &batchLimiter{ramp: c.config.RampBatchConcurrency, limit: concurrency, max: concurrency}
+	if l.ramp {
+		l.limit = 1
+	}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a request can be started.
+func (l *batchLimiter) acquire() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.active >= l.limit {
+		l.cond.Wait()
+	}
+	l.active++
+}
+
+// release marks a request started with acquire as done, adjusting the limit to its outcome.
+func (l *batchLimiter) release(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	if l.ramp {
+		switch {
+		case isRateLimited(err):
+			l.limit /= 2
+			if l.limit < 1 {
+				l.limit = 1
+			}
+		case err == nil && l.limit < l.max:
+			l.limit++
+		}
+	}
+	l.cond.Broadcast()
+}
+
+// isRateLimited reports whether err is an API error with a 429 status.
+func isRateLimited(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	var reqErr *RequestError
+	return errors.As(err, &reqErr) && reqErr.HTTPStatusCode == http.StatusTooManyRequests
+}
+
+// StreamVariOptions configures StreamVariImages.
+type StreamVariOptions struct {
+	// Request holds the parameters shared by every variation; its Image is ignored.
+	Request ImageVariRequest
+	// Concurrency is the maximum number of variations in flight, defaults to one.
+	Concurrency int
+}
+
+// VariResult is the outcome of the variation of one image within StreamVariImages.
+type VariResult struct {
+	// Index is the position of the source image.
+	Index    int
+	Response ImageResponse
+	Err      error
+}
+
+// StreamVariImages creates a variation of every image and sends each result on the returned
+// channel as soon as it completes, so that a UI can show variations as they arrive. Results
+// come in completion order and the channel is closed once every image has been handled. The
+// channel is buffered for every result, so callers may stop receiving early without leaking
+// goroutines.
+func (c *Client) StreamVariImages(
+	ctx context.Context,
+	images []io.Reader,
+	options StreamVariOptions,
+) <-chan VariResult {
+	results := make(chan VariResult, len(images))
+	concurrency := options.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	go func() {
+		defer close(results)
+		limiter := c.newBatchLimiter(concurrency)
+		var wg sync.WaitGroup
+		for i, image := range images {
+			request := options.Request
+			request.Image = image
+
+			limiter.acquire()
+			wg.Add(1)
+			go func(i int, request ImageVariRequest) {
+				defer wg.Done()
+				response, err := c.CreateVariImage(ctx, request)
+				limiter.release(err)
+				results <- VariResult{Index: i, Response: response, Err: err}
+			}(i, request)
+		}
+		wg.Wait()
+	}()
+	return results
+}
@@ -0,0 +1,473 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// concurrencyTracker records the maximum number of requests handled at once.
+type concurrencyTracker struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (c *concurrencyTracker) enter() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current++
+	if c.current > c.peak {
+		c.peak = c.current
+	}
+}
+
+func (c *concurrencyTracker) leave() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.current--
+}
+
+// handleEchoPromptEndpoint responds with the request prompt as the revised prompt.
+func handleEchoPromptEndpoint(tracker *concurrencyTracker) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tracker.enter()
+		defer tracker.leave()
+		time.Sleep(10 * time.Millisecond)
+
+		var imageReq openai.ImageRequest
+		if err := json.NewDecoder(r.Body).Decode(&imageReq); err != nil {
+			http.Error(w, "could not read request", http.StatusInternalServerError)
+			return
+		}
+		res := openai.ImageResponse{
+			Created: time.Now().Unix(),
+			Data:    []openai.ImageResponseDataInner{{RevisedPrompt: imageReq.Prompt}},
+		}
+		resBytes, _ := json.Marshal(res)
+		fmt.Fprintln(w, string(resBytes))
+	}
+}
+
+func TestCreateImagesFromPrompts(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	tracker := &concurrencyTracker{}
+	server.RegisterHandler("/v1/images/generations", handleEchoPromptEndpoint(tracker))
+
+	prompts := []string{"a cat", "a dog", "a bird", "a fish", "a horse"}
+	results, err := client.CreateImagesFromPrompts(context.Background(), prompts, openai.ImageRequest{
+		Model: openai.CreateImageModelDallE2,
+		N:     1,
+	}, 2)
+	checks.NoError(t, err, "CreateImagesFromPrompts error")
+
+	if len(results) != len(prompts) {
+		t.Fatalf("expected %d results, got %d", len(prompts), len(results))
+	}
+	for i, result := range results {
+		checks.NoError(t, result.Err, "generation error")
+		if got := result.Response.Data[0].RevisedPrompt; got != prompts[i] {
+			t.Errorf("result %d: expected prompt %q, got %q", i, prompts[i], got)
+		}
+	}
+	if tracker.peak > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", tracker.peak)
+	}
+}
+
+func TestCreateImagesFromPromptsEmptyPrompt(t *testing.T) {
+	client := openai.NewClient("")
+	_, err := client.CreateImagesFromPrompts(context.Background(), []string{"a cat", " "}, openai.ImageRequest{}, 1)
+	if !errors.Is(err, openai.ErrEmptyImagePrompt) {
+		t.Fatalf("expected ErrEmptyImagePrompt, got %v", err)
+	}
+}
+
+func TestEditDir(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		config, err := png.DecodeConfig(file)
+		if err != nil {
+			http.Error(w, "not a png", http.StatusBadRequest)
+			return
+		}
+		res := openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+			{RevisedPrompt: fmt.Sprintf("%s %dx%d", r.FormValue("prompt"), config.Width, config.Height)},
+		}}
+		resBytes, _ := json.Marshal(res)
+		fmt.Fprintln(w, string(resBytes))
+	})
+
+	dir := t.TempDir()
+	writeFile := func(name string, data []byte) {
+		checks.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600), "WriteFile error")
+	}
+	writeFile("small.png", encodeTestPNG(t, 2, 2))
+	writeFile("large.png", encodeTestPNG(t, 4, 4))
+	writeFile("notes.txt", []byte("not an image"))
+
+	results, err := client.EditDir(context.Background(), dir, openai.ImageEditRequest{
+		Prompt: "remove background",
+		// Every file should be uploaded instead of the image of the request.
+		ImageProvider: func() (io.Reader, error) {
+			return bytes.NewReader(encodeTestPNG(t, 8, 8)), nil
+		},
+		ImageLength: 1,
+	}, 2)
+	checks.NoError(t, err, "EditDir error")
+
+	expected := map[string]string{
+		"small.png": "remove background 2x2",
+		"large.png": "remove background 4x4",
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d: %v", len(expected), len(results), results)
+	}
+	for name, prompt := range expected {
+		if got := results[name].Data[0].RevisedPrompt; got != prompt {
+			t.Errorf("%s: expected %q, got %q", name, prompt, got)
+		}
+	}
+}
+
+func TestCreateImagesPerRequestModel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var mu sync.Mutex
+	models := make(map[string]string)
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		var imageReq openai.ImageRequest
+		if err := json.NewDecoder(r.Body).Decode(&imageReq); err != nil {
+			http.Error(w, "could not read request", http.StatusInternalServerError)
+			return
+		}
+		mu.Lock()
+		models[imageReq.Prompt] = imageReq.Model
+		mu.Unlock()
+		fmt.Fprintln(w, `{"data":[{"url":"https://example.com/image.png"}]}`)
+	})
+
+	requests := []openai.ImageRequest{
+		{Prompt: "a cat", Model: openai.CreateImageModelDallE3, Quality: openai.CreateImageQualityHD},
+		{Prompt: "a dog", Model: openai.CreateImageModelGptImage1, Quality: openai.CreateImageQualityHigh},
+	}
+	results, err := client.CreateImages(context.Background(), requests, 2)
+	checks.NoError(t, err, "CreateImages error")
+	for i, result := range results {
+		checks.NoError(t, result.Err, "generation error")
+		if got := models[requests[i].Prompt]; got != requests[i].Model {
+			t.Errorf("request %d: expected model %q, got %q", i, requests[i].Model, got)
+		}
+	}
+}
+
+func TestCreateImagesValidatesEachRequest(t *testing.T) {
+	client := openai.NewClient("")
+	_, err := client.CreateImages(context.Background(), []openai.ImageRequest{
+		{Prompt: "a cat", Model: openai.CreateImageModelGptImage1, Quality: openai.CreateImageQualityHigh},
+		{Prompt: "a dog", Model: openai.CreateImageModelDallE3, Quality: openai.CreateImageQualityHigh},
+	}, 1)
+	if !errors.Is(err, openai.ErrUnsupportedImageQuality) {
+		t.Fatalf("expected ErrUnsupportedImageQuality, got %v", err)
+	}
+}
+
+func TestCreateImagesRampConcurrency(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RampBatchConcurrency = true
+	})
+	defer teardown()
+
+	tracker := &concurrencyTracker{}
+	echo := handleEchoPromptEndpoint(tracker)
+	var (
+		mu       sync.Mutex
+		arrivals int
+		inFlight []int
+	)
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals++
+		arrival := arrivals
+		tracker.mu.Lock()
+		inFlight = append(inFlight, tracker.current)
+		tracker.mu.Unlock()
+		mu.Unlock()
+
+		// The first two requests are rate limited.
+		if arrival <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprintln(w, `{"error":{"message":"rate limited","type":"rate_limit_error"}}`)
+			return
+		}
+		echo(w, r)
+	})
+
+	prompts := []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"}
+	results, err := client.CreateImagesFromPrompts(context.Background(), prompts, openai.ImageRequest{}, 4)
+	checks.NoError(t, err, "CreateImagesFromPrompts error")
+
+	for i, result := range results {
+		if i < 2 {
+			checks.HasError(t, result.Err, "rate limited generations should fail")
+			continue
+		}
+		checks.NoError(t, result.Err, "generation error")
+	}
+	// Requests start one at a time and stay that way while rate limited.
+	for i := 0; i < 3; i++ {
+		if inFlight[i] != 0 {
+			t.Errorf("expected request %d to run alone, %d others were in flight", i, inFlight[i])
+		}
+	}
+	// Concurrency recovers once requests succeed, without exceeding the batch concurrency.
+	if tracker.peak < 2 || tracker.peak > 4 {
+		t.Errorf("expected concurrency to ramp up to between 2 and 4, got %d", tracker.peak)
+	}
+}
+
+func TestStreamVariImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/variations", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		config, err := png.DecodeConfig(file)
+		if err != nil {
+			http.Error(w, "not a png", http.StatusBadRequest)
+			return
+		}
+		res := openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+			{RevisedPrompt: fmt.Sprintf("%d", config.Width)},
+		}}
+		resBytes, _ := json.Marshal(res)
+		fmt.Fprintln(w, string(resBytes))
+	})
+
+	images := []io.Reader{
+		bytes.NewReader(encodeTestPNG(t, 1, 1)),
+		bytes.NewReader(nil),
+		bytes.NewReader(encodeTestPNG(t, 3, 1)),
+	}
+	results := client.StreamVariImages(context.Background(), images, openai.StreamVariOptions{Concurrency: 2})
+
+	seen := make(map[int]openai.VariResult)
+	for result := range results {
+		seen[result.Index] = result
+	}
+	if len(seen) != len(images) {
+		t.Fatalf("expected a result for every image, got %v", seen)
+	}
+	for _, i := range []int{0, 2} {
+		checks.NoError(t, seen[i].Err, "variation error")
+		if got := seen[i].Response.Data[0].RevisedPrompt; got != fmt.Sprintf("%d", i+1) {
+			t.Errorf("expected result %d to come from image %d, got width %s", i, i, got)
+		}
+	}
+	checks.ErrorIs(t, seen[1].Err, openai.ErrEmptyImage, "the empty image should fail")
+}
+
+func TestCreateImagesReport(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		var imageReq openai.ImageRequest
+		if err := json.NewDecoder(r.Body).Decode(&imageReq); err != nil {
+			http.Error(w, "could not read request", http.StatusInternalServerError)
+			return
+		}
+		switch imageReq.Prompt {
+		case "blocked":
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":"content_policy_violation","message":"blocked","type":"invalid_request_error"}}`)
+			return
+		case "busy":
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"error":{"code":"rate_limit_exceeded","message":"slow down","type":"requests"}}`)
+			return
+		}
+		res := openai.ImageResponse{
+			Data: []openai.ImageResponseDataInner{{B64JSON: "aW1hZ2U="}, {B64JSON: "aW1hZ2U="}},
+			Usage: openai.ImageResponseUsage{
+				TotalTokens:        1010,
+				InputTokens:        10,
+				OutputTokens:       1000,
+				InputTokensDetails: openai.ImageResponseInputTokensDetails{TextTokens: 10},
+			},
+		}
+		resBytes, _ := json.Marshal(res)
+		fmt.Fprintln(w, string(resBytes))
+	})
+
+	prompts := []string{"a cat", "blocked", "a dog", "busy", "blocked"}
+	results, err := client.CreateImagesFromPrompts(context.Background(), prompts, openai.ImageRequest{
+		Model: openai.CreateImageModelGptImage1,
+		N:     2,
+	}, 2)
+	checks.NoError(t, err, "CreateImagesFromPrompts error")
+
+	report := results.Report()
+	if report.Images != 4 || report.Succeeded != 2 || report.Failed != 3 {
+		t.Errorf("expected 4 images from 2 successes and 3 failures, got %+v", report)
+	}
+	if report.Usage.TotalTokens != 2020 || report.Usage.OutputTokens != 2000 ||
+		report.Usage.InputTokensDetails.TextTokens != 20 {
+		t.Errorf("unexpected aggregated usage %+v", report.Usage)
+	}
+	// 20 text input tokens at $5/M and 2000 output tokens at $40/M.
+	if want := 0.0801; report.EstimatedCost < want-1e-9 || report.EstimatedCost > want+1e-9 {
+		t.Errorf("expected an estimated cost of %v, got %v", want, report.EstimatedCost)
+	}
+	if report.Errors["content_policy_violation"] != 2 || report.Errors["rate_limit_exceeded"] != 1 ||
+		len(report.Errors) != 2 {
+		t.Errorf("unexpected error breakdown %v", report.Errors)
+	}
+}
+
+func TestCreateImagesCheckpoint(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var (
+		mu      sync.Mutex
+		crashed = true
+		sent    []string
+	)
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		imageReq, err := getImageBody(r)
+		if err != nil {
+			http.Error(w, "could not read request", http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		sent = append(sent, imageReq.Prompt)
+		fail := crashed && (imageReq.Prompt == "a dog" || imageReq.Prompt == "a fish")
+		mu.Unlock()
+		if fail {
+			http.Error(w, `{"error":{"message":"server crashed","type":"server_error"}}`, http.StatusInternalServerError)
+			return
+		}
+		resBytes, _ := json.Marshal(openai.ImageResponse{
+			Data: []openai.ImageResponseDataInner{{RevisedPrompt: imageReq.Prompt}},
+		})
+		fmt.Fprintln(w, string(resBytes))
+	})
+
+	path := filepath.Join(t.TempDir(), "batch.checkpoint")
+	ctx := openai.WithCallOptions(context.Background(), openai.WithBatchCheckpoint(path))
+	prompts := []string{"a cat", "a dog", "a bird", "a fish"}
+	base := openai.ImageRequest{Model: openai.CreateImageModelDallE2, N: 1}
+
+	results, err := client.CreateImagesFromPrompts(ctx, prompts, base, 2)
+	checks.NoError(t, err, "CreateImagesFromPrompts error")
+	if report := results.Report(); report.Succeeded != 2 || report.Failed != 2 {
+		t.Fatalf("expected the first run to fail halfway, got %+v", report)
+	}
+
+	// Simulate a crash while the last entry was being written.
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	checks.NoError(t, err, "could not open checkpoint")
+	_, err = file.WriteString(`{"key":"0123","response":{"da`)
+	checks.NoError(t, err, "could not write checkpoint")
+	checks.NoError(t, file.Close(), "could not close checkpoint")
+
+	// takeSent returns the prompts sent since the last call and stops the simulated crash.
+	takeSent := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		prompts := sent
+		crashed, sent = false, nil
+		return prompts
+	}
+	takeSent()
+
+	results, err = client.CreateImagesFromPrompts(ctx, prompts, base, 2)
+	checks.NoError(t, err, "CreateImagesFromPrompts rerun error")
+	rerun := takeSent()
+	sort.Strings(rerun)
+	if len(rerun) != 2 || rerun[0] != "a dog" || rerun[1] != "a fish" {
+		t.Errorf("expected the rerun to only send the failed prompts, sent %q", rerun)
+	}
+	for i, result := range results {
+		if result.Err != nil || len(result.Response.Data) != 1 || result.Response.Data[0].RevisedPrompt != prompts[i] {
+			t.Errorf("result %d: unexpected %+v", i, result)
+		}
+	}
+
+	// Every generation is recorded now, so a third run sends nothing.
+	_, err = client.CreateImagesFromPrompts(ctx, prompts, base, 2)
+	checks.NoError(t, err, "CreateImagesFromPrompts third run error")
+	if third := takeSent(); len(third) != 0 {
+		t.Errorf("expected no request on the third run, sent %q", third)
+	}
+}
+
+func TestCreateImagesCheckpointDuplicateRequests(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	calls := 0
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls == 2 || calls == 3 {
+			http.Error(w, `{"error":{"message":"server crashed","type":"server_error"}}`, http.StatusInternalServerError)
+			return
+		}
+		resBytes, _ := json.Marshal(openai.ImageResponse{
+			Data: []openai.ImageResponseDataInner{{RevisedPrompt: fmt.Sprintf("variant %d", calls)}},
+		})
+		fmt.Fprintln(w, string(resBytes))
+	})
+
+	path := filepath.Join(t.TempDir(), "batch.checkpoint")
+	ctx := openai.WithCallOptions(context.Background(), openai.WithBatchCheckpoint(path))
+	request := openai.ImageRequest{Prompt: "a cat", Model: openai.CreateImageModelDallE2, N: 1}
+	requests := []openai.ImageRequest{request, request, request}
+
+	results, err := client.CreateImages(ctx, requests, 1)
+	checks.NoError(t, err, "CreateImages error")
+	if report := results.Report(); report.Succeeded != 1 || report.Failed != 2 {
+		t.Fatalf("expected only the first repeat to succeed, got %+v", report)
+	}
+
+	results, err = client.CreateImages(ctx, requests, 1)
+	checks.NoError(t, err, "CreateImages rerun error")
+	if calls != 5 {
+		t.Errorf("expected the rerun to regenerate both failed repeats, got %d calls in total", calls)
+	}
+	seen := make(map[string]bool)
+	for i, result := range results {
+		if result.Err != nil || len(result.Response.Data) != 1 {
+			t.Fatalf("result %d: unexpected %+v", i, result)
+		}
+		seen[result.Response.Data[0].RevisedPrompt] = true
+	}
+	if len(seen) != len(requests) {
+		t.Errorf("expected every repeat to get its own image, got %v", seen)
+	}
+}
@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// ImageCache stores image generation responses keyed by HashImageRequest.
+// When set on ClientConfig, CreateImage serves identical requests from the cache.
+type ImageCache interface {
+	Get(key string) (ImageResponse, bool)
+	Set(key string, response ImageResponse)
+}
+
+// MemoryImageCache is an ImageCache backed by an in-memory map. It is safe for concurrent use.
+type MemoryImageCache struct {
+	mu        sync.RWMutex
+	responses map[string]ImageResponse
+}
+
+// NewMemoryImageCache creates an empty in-memory image cache.
+func NewMemoryImageCache() *MemoryImageCache {
+	return &MemoryImageCache{
+		responses: make(map[string]ImageResponse),
+	}
+}
+
+func (m *MemoryImageCache) Get(key string) (ImageResponse, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	response, ok := m.responses[key]
+	return response, ok
+}
+
+func (m *MemoryImageCache) Set(key string, response ImageResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[key] = response
+}
+
+// HashImageRequest returns a stable hex-encoded SHA-256 hash of the fields of a generation
+// request that affect its result, for use as a cache key or to correlate logs. Fields that do
+// not change the generated images, such as User, are left out, and the size is normalized, so
+// "1024 X 1024" and "1024x1024" hash the same.
+func HashImageRequest(r ImageRequest) string {
+	r.User = ""
+	r.Size = normalizeRequestSize(r.Size)
+
+	// Round-tripping through a map sorts the keys, so the hash does not depend on field order.
+	var fields map[string]any
+	data, err := json.Marshal(r)
+	if err == nil {
+		err = json.Unmarshal(data, &fields)
+	}
+	if err == nil {
+		data, err = json.Marshal(fields)
+	}
+	if err != nil {
+		// ExtraFields holds a value JSON cannot encode, fall back to its printed form.
+		data = []byte(fmt.Sprintf("%#v", r))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
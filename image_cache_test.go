@@ -0,0 +1,77 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestImageCache(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.ImageCache = openai.NewMemoryImageCache()
+	})
+	defer teardown()
+
+	calls := 0
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		handleImageEndpoint(w, r)
+	})
+
+	request := openai.ImageRequest{
+		Prompt:         "Lorem ipsum",
+		N:              2,
+		ResponseFormat: openai.CreateImageResponseFormatURL,
+	}
+	first, err := client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error")
+	second, err := client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error")
+
+	if calls != 1 {
+		t.Fatalf("expected the second request to be served from cache, server was called %d times", calls)
+	}
+	if len(second.Data) != len(first.Data) || second.Created != first.Created {
+		t.Error("cached response does not match the original response")
+	}
+
+	request.Prompt = "Dolor sit amet"
+	_, err = client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error")
+	if calls != 2 {
+		t.Errorf("expected a different request to reach the server, server was called %d times", calls)
+	}
+}
+
+func TestHashImageRequest(t *testing.T) {
+	request := openai.ImageRequest{
+		Prompt:  "Lorem ipsum",
+		Model:   openai.CreateImageModelDallE3,
+		Size:    openai.CreateImageSize1024x1024,
+		Quality: openai.CreateImageQualityHD,
+		User:    "user-1",
+	}
+	equivalent := request
+	equivalent.User = "user-2"
+	equivalent.Size = "1024 X 1024"
+	if openai.HashImageRequest(request) != openai.HashImageRequest(equivalent) {
+		t.Error("expected requests differing only in user and size formatting to hash identically")
+	}
+
+	different := request
+	different.Quality = openai.CreateImageQualityStandard
+	if openai.HashImageRequest(request) == openai.HashImageRequest(different) {
+		t.Error("expected requests with a different quality to hash differently")
+	}
+
+	withExtra := request
+	withExtra.ExtraFields = map[string]any{"a": 1, "b": 2}
+	withExtraReordered := request
+	withExtraReordered.ExtraFields = map[string]any{"b": 2, "a": 1}
+	if openai.HashImageRequest(withExtra) != openai.HashImageRequest(withExtraReordered) {
+		t.Error("expected the hash not to depend on field order")
+	}
+}
@@ -0,0 +1,87 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// checkpointEntry is a line of a batch checkpoint file.
+type checkpointEntry struct {
+	Key      string        `json:"key"`
+	Response ImageResponse `json:"response"`
+}
+
+// checkpointKey identifies the request at index i of a batch. The index tells identical
+// requests apart, such as repeats for variety, so that each of them gets its own image.
+func checkpointKey(i int, request ImageRequest) string {
+	return fmt.Sprintf("%d:%s", i, HashImageRequest(request))
+}
+
+// batchCheckpoint records the completed generations of a batch, see WithBatchCheckpoint.
+type batchCheckpoint struct {
+	mu        sync.Mutex
+	file      *os.File
+	completed map[string]ImageResponse
+}
+
+// openBatchCheckpoint loads the generations recorded in the checkpoint file at path, creating
+// it if needed. An entry cut short by a crash ends the file; it is dropped so that the entries
+// recorded from now on can be read back.
+func openBatchCheckpoint(path string) (*batchCheckpoint, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint := &batchCheckpoint{file: file, completed: make(map[string]ImageResponse)}
+	decoder := json.NewDecoder(file)
+	var valid int64
+	for {
+		var entry checkpointEntry
+		if decodeErr := decoder.Decode(&entry); decodeErr != nil {
+			break
+		}
+		checkpoint.completed[entry.Key] = entry.Response
+		valid = decoder.InputOffset()
+	}
+
+	if err = file.Truncate(valid); err == nil {
+		_, err = file.Seek(valid, io.SeekStart)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return checkpoint, nil
+}
+
+// lookup returns the recorded response of the request with the given key.
+func (c *batchCheckpoint) lookup(key string) (ImageResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	response, ok := c.completed[key]
+	return response, ok
+}
+
+// record appends a completed generation to the checkpoint file.
+func (c *batchCheckpoint) record(key string, response ImageResponse) error {
+	data, err := json.Marshal(checkpointEntry{Key: key, Response: response})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err = c.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	c.completed[key] = response
+	return nil
+}
+
+func (c *batchCheckpoint) close() error {
+	return c.file.Close()
+}
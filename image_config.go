@@ -0,0 +1,141 @@
+package openai
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrUnsupportedConfigFormat = errors.New("unsupported config format, expected json or yaml")
+
+// LoadImageRequest reads an ImageRequest from a JSON or YAML config, using the same field names
+// as the API (prompt, model, size, ...), and validates it. format is "json", "yaml" or "yml".
+// YAML support covers flat "key: value" mappings with optional quoting and comments, which is
+// all an ImageRequest needs; anchors, nested mappings and multi-line strings are not supported.
+func LoadImageRequest(r io.Reader, format string) (request ImageRequest, err error) {
+	switch strings.ToLower(format) {
+	case "json":
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&request)
+	case "yaml", "yml":
+		err = decodeFlatYAML(r, &request)
+	default:
+		err = fmt.Errorf("%w: %q", ErrUnsupportedConfigFormat, format)
+	}
+	if err != nil {
+		return ImageRequest{}, err
+	}
+
+	err = newValidationError(request.Validate())
+	if err != nil {
+		return ImageRequest{}, err
+	}
+	return request, nil
+}
+
+// decodeFlatYAML decodes a flat YAML mapping into the fields of the struct pointed to by v,
+// matching keys against the fields' json tags.
+func decodeFlatYAML(r io.Reader, v any) error {
+	fields := jsonFieldsByName(reflect.ValueOf(v).Elem())
+
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(stripYAMLComment(scanner.Text()))
+		if line == "" || line == "---" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("yaml line %d: expected key: value", lineNumber)
+		}
+		key = strings.TrimSpace(key)
+		field, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("yaml line %d: unknown field %q", lineNumber, key)
+		}
+		if err := setYAMLScalar(field, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("yaml line %d: field %q: %w", lineNumber, key, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// jsonFieldsByName maps the json names of the exported fields of a struct to their values.
+func jsonFieldsByName(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	for i := 0; i < v.NumField(); i++ {
+		name, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" && v.Field(i).CanSet() {
+			fields[name] = v.Field(i)
+		}
+	}
+	return fields
+}
+
+// stripYAMLComment removes a trailing comment that is not inside a quoted value.
+func stripYAMLComment(line string) string {
+	var quote rune
+	for i, ch := range line {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func setYAMLScalar(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		elem := reflect.New(field.Type().Elem())
+		if err := setYAMLScalar(elem.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(elem)
+		return nil
+	}
+
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		if value[0] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return err
+			}
+			value = unquoted
+		} else {
+			value = strings.ReplaceAll(value[1:len(value)-1], "''", "'")
+		}
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Kind())
+	}
+	return nil
+}
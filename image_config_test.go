@@ -0,0 +1,82 @@
+package openai_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestLoadImageRequestJSON(t *testing.T) {
+	config := `{
+		"prompt": "A lighthouse at dusk",
+		"model": "dall-e-3",
+		"n": 1,
+		"size": "1792x1024",
+		"quality": "hd",
+		"style": "natural"
+	}`
+	request, err := openai.LoadImageRequest(strings.NewReader(config), "json")
+	checks.NoError(t, err, "LoadImageRequest error")
+
+	expected := openai.ImageRequest{
+		Prompt:  "A lighthouse at dusk",
+		Model:   openai.CreateImageModelDallE3,
+		N:       1,
+		Size:    openai.CreateImageSize1792x1024,
+		Quality: openai.CreateImageQualityHD,
+		Style:   openai.CreateImageStyleNatural,
+	}
+	if !reflect.DeepEqual(request, expected) {
+		t.Errorf("unexpected request %+v", request)
+	}
+}
+
+func TestLoadImageRequestYAML(t *testing.T) {
+	config := `
+# generation job
+prompt: "A lighthouse at dusk # not a comment"
+model: gpt-image-1
+n: 2
+size: 1536x1024 # landscape
+background: 'transparent'
+output_format: webp
+output_compression: 80
+user: "12345"
+seed: 7
+`
+	request, err := openai.LoadImageRequest(strings.NewReader(config), "yaml")
+	checks.NoError(t, err, "LoadImageRequest error")
+
+	seed := 7
+	expected := openai.ImageRequest{
+		Prompt:            "A lighthouse at dusk # not a comment",
+		Model:             openai.CreateImageModelGptImage1,
+		N:                 2,
+		Size:              openai.CreateImageSize1536x1024,
+		Background:        openai.CreateImageBackgroundTransparent,
+		OutputFormat:      openai.CreateImageOutputFormatWEBP,
+		OutputCompression: 80,
+		User:              "12345",
+		Seed:              &seed,
+	}
+	if !reflect.DeepEqual(request, expected) {
+		t.Errorf("unexpected request %+v", request)
+	}
+}
+
+func TestLoadImageRequestErrors(t *testing.T) {
+	_, err := openai.LoadImageRequest(strings.NewReader(`prompt: cat`), "toml")
+	checks.ErrorIs(t, err, openai.ErrUnsupportedConfigFormat, "unknown formats should be rejected")
+
+	_, err = openai.LoadImageRequest(strings.NewReader(`colour: red`), "yaml")
+	checks.HasError(t, err, "unknown fields should be rejected")
+
+	_, err = openai.LoadImageRequest(strings.NewReader(`{"prompt":"cat","unknown":1}`), "json")
+	checks.HasError(t, err, "unknown fields should be rejected")
+
+	_, err = openai.LoadImageRequest(strings.NewReader("model: dall-e-3\nn: 2"), "yml")
+	checks.ErrorIs(t, err, openai.ErrImageNUnsupported, "loaded requests should be validated")
+}
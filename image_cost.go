@@ -0,0 +1,169 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+)
+
+var ErrImageBudgetExceeded = errors.New("image token budget exceeded")
+
+// charsPerToken is the rule of thumb for the average number of characters of English text per token.
+const charsPerToken = 4
+
+// EstimatePromptTokens returns a rough estimate of the text input tokens gpt-image-1 bills for
+// prompt, reported as ImageResponseUsage.InputTokensDetails.TextTokens. It is based on the
+// four-characters-per-token rule of thumb rather than an actual tokenizer, so treat it as an
+// estimate only.
+func EstimatePromptTokens(prompt string) int {
+	return (utf8.RuneCountInString(prompt) + charsPerToken - 1) / charsPerToken
+}
+
+// EnforceBudget returns an error wrapping ErrImageBudgetExceeded if the usage is over maxTokens.
+func (u ImageResponseUsage) EnforceBudget(maxTokens int) error {
+	if u.TotalTokens > maxTokens {
+		return fmt.Errorf("%w: used %d of %d tokens", ErrImageBudgetExceeded, u.TotalTokens, maxTokens)
+	}
+	return nil
+}
+
+// UsageBreakdown is a flattened view of ImageResponseUsage, convenient for logging.
+type UsageBreakdown struct {
+	TextInputTokens  int
+	ImageInputTokens int
+	OutputTokens     int
+	TotalTokens      int
+}
+
+// Breakdown returns the usage with the input token details flattened.
+func (u ImageResponseUsage) Breakdown() UsageBreakdown {
+	return UsageBreakdown{
+		TextInputTokens:  u.InputTokensDetails.TextTokens,
+		ImageInputTokens: u.InputTokensDetails.ImageTokens,
+		OutputTokens:     u.OutputTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
+
+// ImageBudget tracks the cumulative token usage of image generations against a limit.
+// Set it as ClientConfig.ImageBudget to stop generating once the limit has been exceeded:
+// the generation that crosses the limit completes, and every later one fails with
+// ErrImageBudgetExceeded without calling the API. It is safe for concurrent use.
+type ImageBudget struct {
+	maxTokens int
+
+	mu    sync.Mutex
+	usage ImageResponseUsage
+}
+
+// NewImageBudget creates an ImageBudget allowing up to maxTokens total tokens.
+func NewImageBudget(maxTokens int) *ImageBudget {
+	return &ImageBudget{maxTokens: maxTokens}
+}
+
+// Usage returns the cumulative usage recorded so far.
+func (b *ImageBudget) Usage() ImageResponseUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.usage
+}
+
+// Check returns an error wrapping ErrImageBudgetExceeded once the recorded usage is over the limit.
+func (b *ImageBudget) Check() error {
+	return b.Usage().EnforceBudget(b.maxTokens)
+}
+
+// Add records the usage of a completed generation.
+func (b *ImageBudget) Add(usage ImageResponseUsage) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.usage = b.usage.plus(usage)
+}
+
+// plus returns the sum of two usages.
+func (u ImageResponseUsage) plus(other ImageResponseUsage) ImageResponseUsage {
+	u.TotalTokens += other.TotalTokens
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.InputTokensDetails.TextTokens += other.InputTokensDetails.TextTokens
+	u.InputTokensDetails.ImageTokens += other.InputTokensDetails.ImageTokens
+	return u
+}
+
+// gpt-image-1 prices in US dollars per million tokens.
+const (
+	gptImage1TextInputPrice  = 5.0
+	gptImage1ImageInputPrice = 10.0
+	gptImage1OutputPrice     = 40.0
+	tokensPerPriceUnit       = 1_000_000
+)
+
+// EstimateCost returns the cost of the usage in US dollars at the published gpt-image-1
+// token prices. Only gpt-image-1 reports usage, so dall-e generations cost nothing here.
+func (u ImageResponseUsage) EstimateCost() float64 {
+	return (float64(u.InputTokensDetails.TextTokens)*gptImage1TextInputPrice +
+		float64(u.InputTokensDetails.ImageTokens)*gptImage1ImageInputPrice +
+		float64(u.OutputTokens)*gptImage1OutputPrice) / tokensPerPriceUnit
+}
+
+// gptImage1OutputTokens is the number of output tokens gpt-image-1 bills per image, by quality and size.
+var gptImage1OutputTokens = map[string]map[string]int{
+	CreateImageQualityLow: {
+		CreateImageSize1024x1024: 272,
+		CreateImageSize1024x1536: 408,
+		CreateImageSize1536x1024: 400,
+	},
+	CreateImageQualityMedium: {
+		CreateImageSize1024x1024: 1056,
+		CreateImageSize1024x1536: 1584,
+		CreateImageSize1536x1024: 1568,
+	},
+	CreateImageQualityHigh: {
+		CreateImageSize1024x1024: 4160,
+		CreateImageSize1024x1536: 6240,
+		CreateImageSize1536x1024: 6208,
+	},
+}
+
+// QualityForBudget returns the highest gpt-image-1 quality whose output tokens for one image
+// of the given size fit in maxTokens. The estimate covers output tokens only, so leave room
+// for the prompt in maxTokens. An empty or "auto" size is priced at its most expensive
+// resolution. If even low quality does not fit, the error wraps ErrImageBudgetExceeded.
+func QualityForBudget(size string, maxTokens int) (string, error) {
+	if size == "" {
+		size = CreateImageSizeAuto
+	}
+	normalized, err := NormalizeSize(size)
+	if err != nil {
+		return "", err
+	}
+	if info, _ := lookupImageModel(CreateImageModelGptImage1); !info.SupportsSize(normalized) {
+		return "", fmt.Errorf("%w: %s for %s", ErrUnsupportedImageSize, normalized, CreateImageModelGptImage1)
+	}
+
+	var tokens int
+	for _, quality := range []string{CreateImageQualityHigh, CreateImageQualityMedium, CreateImageQualityLow} {
+		tokens = estimateOutputTokens(quality, normalized)
+		if tokens <= maxTokens {
+			return quality, nil
+		}
+	}
+	return "", fmt.Errorf("%w: a %s image needs %d output tokens at %s quality, the budget is %d",
+		ErrImageBudgetExceeded, normalized, tokens, CreateImageQualityLow, maxTokens)
+}
+
+// estimateOutputTokens returns the gpt-image-1 output tokens of one image, using the most
+// expensive size for "auto".
+func estimateOutputTokens(quality, size string) int {
+	if size != CreateImageSizeAuto {
+		return gptImage1OutputTokens[quality][size]
+	}
+	var tokens int
+	for _, sizeTokens := range gptImage1OutputTokens[quality] {
+		if sizeTokens > tokens {
+			tokens = sizeTokens
+		}
+	}
+	return tokens
+}
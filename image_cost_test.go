@@ -0,0 +1,124 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestEstimatePromptTokens(t *testing.T) {
+	if tokens := openai.EstimatePromptTokens(""); tokens != 0 {
+		t.Errorf("expected 0 tokens for an empty prompt, got %d", tokens)
+	}
+	if tokens := openai.EstimatePromptTokens("a"); tokens != 1 {
+		t.Errorf("expected 1 token for a single character, got %d", tokens)
+	}
+
+	previous := 0
+	for i := 1; i <= 200; i++ {
+		tokens := openai.EstimatePromptTokens(strings.Repeat("cat ", i))
+		if tokens < previous {
+			t.Fatalf("estimate decreased from %d to %d at %d words", previous, tokens, i)
+		}
+		previous = tokens
+	}
+	if previous <= openai.EstimatePromptTokens("cat") {
+		t.Error("expected longer prompts to estimate more tokens")
+	}
+}
+
+func TestEnforceBudget(t *testing.T) {
+	usage := openai.ImageResponseUsage{TotalTokens: 100}
+	checks.NoError(t, usage.EnforceBudget(100), "usage at the budget should be allowed")
+	checks.ErrorIs(t, usage.EnforceBudget(99), openai.ErrImageBudgetExceeded, "usage over the budget should fail")
+}
+
+func TestImageBudgetAbortsBatch(t *testing.T) {
+	budget := openai.NewImageBudget(150)
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.ImageBudget = budget
+	})
+	defer teardown()
+
+	requests := 0
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		res := openai.ImageResponse{
+			Data:  []openai.ImageResponseDataInner{{URL: "https://example.com/image.png"}},
+			Usage: openai.ImageResponseUsage{TotalTokens: 100, OutputTokens: 100},
+		}
+		resBytes, _ := json.Marshal(res)
+		fmt.Fprintln(w, string(resBytes))
+	})
+
+	prompts := []string{"a cat", "a dog", "a bird", "a fish"}
+	results, err := client.CreateImagesFromPrompts(context.Background(), prompts, openai.ImageRequest{}, 1)
+	checks.NoError(t, err, "CreateImagesFromPrompts error")
+
+	for i, result := range results {
+		overBudget := errors.Is(result.Err, openai.ErrImageBudgetExceeded)
+		if overBudget != (i >= 2) {
+			t.Errorf("result %d: unexpected error %v", i, result.Err)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected the batch to stop after 2 requests, got %d", requests)
+	}
+	if usage := budget.Usage(); usage.TotalTokens != 200 || usage.OutputTokens != 200 {
+		t.Errorf("unexpected cumulative usage %+v", usage)
+	}
+}
+
+func TestUsageBreakdown(t *testing.T) {
+	var usage openai.ImageResponseUsage
+	err := json.Unmarshal([]byte(`{
+		"total_tokens": 4210,
+		"input_tokens": 50,
+		"output_tokens": 4160,
+		"input_tokens_details": {"text_tokens": 40, "image_tokens": 10}
+	}`), &usage)
+	checks.NoError(t, err, "Unmarshal error")
+
+	expected := openai.UsageBreakdown{
+		TextInputTokens:  40,
+		ImageInputTokens: 10,
+		OutputTokens:     4160,
+		TotalTokens:      4210,
+	}
+	if breakdown := usage.Breakdown(); breakdown != expected {
+		t.Errorf("unexpected breakdown %+v", breakdown)
+	}
+}
+
+func TestQualityForBudget(t *testing.T) {
+	cases := []struct {
+		size      string
+		maxTokens int
+		want      string
+	}{
+		{openai.CreateImageSize1024x1024, 500, openai.CreateImageQualityLow},
+		{openai.CreateImageSize1024x1024, 2000, openai.CreateImageQualityMedium},
+		{openai.CreateImageSize1024x1024, 10000, openai.CreateImageQualityHigh},
+		{openai.CreateImageSizeAuto, 6000, openai.CreateImageQualityMedium},
+	}
+	for _, tc := range cases {
+		quality, err := openai.QualityForBudget(tc.size, tc.maxTokens)
+		checks.NoError(t, err, "QualityForBudget error")
+		if quality != tc.want {
+			t.Errorf("expected %s quality for %s within %d tokens, got %s", tc.want, tc.size, tc.maxTokens, quality)
+		}
+	}
+
+	_, err := openai.QualityForBudget(openai.CreateImageSize1024x1024, 100)
+	checks.ErrorIs(t, err, openai.ErrImageBudgetExceeded, "budgets below low quality should fail")
+
+	_, err = openai.QualityForBudget(openai.CreateImageSize1792x1024, 10000)
+	checks.ErrorIs(t, err, openai.ErrUnsupportedImageSize, "sizes gpt-image-1 does not support should fail")
+}
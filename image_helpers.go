@@ -0,0 +1,435 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrNoImageData             = errors.New("image response contains no b64_json data")
+	ErrImageDimensionsMismatch = errors.New("image dimensions do not match the requested size")
+	ErrUpscaleUnsupported      = errors.New("upscaling is not supported by this model")
+	ErrInvalidTileGrid         = errors.New("tile grid must have at least one column and one row")
+	ErrImageNotAccepted        = errors.New("no generated image was accepted")
+	ErrImageDownloadFailed     = errors.New("image download failed")
+)
+
+// sniffLen is the number of bytes http.DetectContentType considers.
+const sniffLen = 512
+
+// SniffingReader detects the content type of r from its first bytes with http.DetectContentType,
+// without buffering the rest of it, and returns a reader yielding the full content of r along
+// with the type. Seekable readers are peeked at and moved back, and returned as is so that they
+// can still be measured and streamed; other readers are wrapped in a reader that replays the
+// peeked bytes. Unknown content is reported as "application/octet-stream" and a nil reader as "".
+func SniffingReader(r io.Reader) (io.Reader, string) {
+	if r == nil {
+		return nil, ""
+	}
+
+	if seeker, ok := r.(io.ReadSeeker); ok {
+		if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+			head := make([]byte, sniffLen)
+			n, _ := io.ReadFull(seeker, head)
+			if _, err = seeker.Seek(offset, io.SeekStart); err == nil {
+				return r, http.DetectContentType(head[:n])
+			}
+		}
+	}
+
+	buffered := bufio.NewReaderSize(r, sniffLen)
+	head, _ := buffered.Peek(sniffLen)
+	return buffered, http.DetectContentType(head)
+}
+
+// sniffImageContentType returns the image content type of r detected with SniffingReader, and
+// an empty type if r is not a recognized image.
+func sniffImageContentType(r io.Reader) (io.Reader, string) {
+	r, contentType := SniffingReader(r)
+	if !strings.HasPrefix(contentType, "image/") {
+		return r, ""
+	}
+	return r, contentType
+}
+
+// StreamImage generates an image and writes the decoded first result straight to w,
+// setting the Content-Type header from the image bytes. dall-e models are asked for
+// b64_json output when no ResponseFormat is set. If the generation fails before any
+// body is written, an error status is sent to w and the error is returned.
+func (c *Client) StreamImage(ctx context.Context, request ImageRequest, w http.ResponseWriter) error {
+	request = withB64ResponseFormat(request)
+
+	response, err := c.CreateImage(ctx, request)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err))
+		return err
+	}
+	if len(response.Data) == 0 || response.Data[0].B64JSON == "" {
+		http.Error(w, ErrNoImageData.Error(), http.StatusBadGateway)
+		return ErrNoImageData
+	}
+
+	decoded := bufio.NewReaderSize(
+		base64.NewDecoder(base64.StdEncoding, strings.NewReader(response.Data[0].B64JSON)),
+		sniffLen,
+	)
+	head, err := decoded.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return err
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(head))
+	w.WriteHeader(http.StatusOK)
+	_, err = io.Copy(w, decoded)
+	return err
+}
+
+// withB64ResponseFormat asks dall-e models for b64_json output when request has no
+// ResponseFormat, so that the images can be decoded without fetching their URLs.
+func withB64ResponseFormat(request ImageRequest) ImageRequest {
+	if request.ResponseFormat == "" && request.Model != CreateImageModelGptImage1 {
+		request.ResponseFormat = CreateImageResponseFormatB64JSON
+	}
+	return request
+}
+
+// errorStatusCode returns the HTTP status to report to downstream clients for err. Requests
+// rejected before being sent are reported as bad requests.
+func errorStatusCode(err error) int {
+	if ErrorKindOf(err) == KindValidation {
+		return http.StatusBadRequest
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode > 0 {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode > 0 {
+		return reqErr.HTTPStatusCode
+	}
+	return http.StatusBadGateway
+}
+
+// VerifyDimensions decodes every image of r and checks that its dimensions match request.Size.
+// URL entries are fetched with the client's HTTP client. Nothing is checked when no size was
+// requested or the size is "auto". Decoding relies on the registered image formats, so WEBP
+// output requires importing golang.org/x/image/webp.
+func (c *Client) VerifyDimensions(ctx context.Context, request ImageRequest, r ImageResponse) error {
+	size := normalizeRequestSize(request.Size)
+	if size == "" || size == CreateImageSizeAuto {
+		return nil
+	}
+	width, height, err := parseImageSize(size)
+	if err != nil {
+		return err
+	}
+
+	for i, d := range r.Data {
+		var data []byte
+		if d.B64JSON != "" {
+			data, err = base64.StdEncoding.DecodeString(d.B64JSON)
+		} else {
+			data, err = c.fetchImage(ctx, d.URL)
+		}
+		if err != nil {
+			return fmt.Errorf("image %d: %w", i, err)
+		}
+
+		var config image.Config
+		config, _, err = image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("image %d: %w", i, err)
+		}
+		if config.Width != width || config.Height != height {
+			return fmt.Errorf("%w: image %d is %dx%d, requested %s",
+				ErrImageDimensionsMismatch, i, config.Width, config.Height, request.Size)
+		}
+	}
+	return nil
+}
+
+// CreateAndUpscale generates an image and then edits the result to targetSize, for OpenAI-compatible
+// servers that upscale through the edit endpoint. The official image models cannot upscale, so
+// ErrUpscaleUnsupported is returned for them without sending any request.
+func (c *Client) CreateAndUpscale(ctx context.Context, request ImageRequest, targetSize string) (ImageResponse, error) {
+	if _, ok := lookupImageModel(request.Model); ok {
+		return ImageResponse{}, newValidationError(fmt.Errorf("%w: %s", ErrUpscaleUnsupported, request.Model))
+	}
+	if request.ResponseFormat == "" {
+		request.ResponseFormat = CreateImageResponseFormatB64JSON
+	}
+
+	generated, err := c.CreateImage(ctx, request)
+	if err != nil {
+		return ImageResponse{}, err
+	}
+	if len(generated.Data) == 0 {
+		return ImageResponse{}, ErrNoImageData
+	}
+
+	var data []byte
+	if generated.Data[0].B64JSON != "" {
+		data, err = base64.StdEncoding.DecodeString(generated.Data[0].B64JSON)
+	} else {
+		data, err = c.fetchImage(ctx, generated.Data[0].URL)
+	}
+	if err != nil {
+		return ImageResponse{}, err
+	}
+
+	edit := ImageEditRequestFromBytes(data, request.Prompt)
+	edit.Model = request.Model
+	edit.N = 1
+	edit.Size = targetSize
+	edit.ResponseFormat = request.ResponseFormat
+	edit.User = request.User
+	return c.CreateEditImage(ctx, edit)
+}
+
+// tileConcurrency is the number of tiles GenerateTiled generates at once.
+const tileConcurrency = 4
+
+// GenerateTiled generates a tilesX by tilesY grid of tileSize images for prompt and stitches
+// them into a single image, for canvases larger than any model can produce. Every tile is
+// generated separately with the prompt and a hint about its position in the grid, at most
+// four at a time so that large grids do not run into rate limits.
+//
+// The tiles are independent generations, so expect visible seams: colors, lighting and
+// objects are not continued across tile edges. Abstract patterns and textures stitch best.
+func (c *Client) GenerateTiled(
+	ctx context.Context,
+	prompt string,
+	tilesX, tilesY int,
+	tileSize string,
+) (image.Image, error) {
+	if tilesX < 1 || tilesY < 1 {
+		return nil, newValidationError(fmt.Errorf("%w, got %dx%d", ErrInvalidTileGrid, tilesX, tilesY))
+	}
+	tileWidth, tileHeight, err := parseImageSize(tileSize)
+	if err != nil {
+		return nil, newValidationError(err)
+	}
+
+	requests := make([]ImageRequest, 0, tilesX*tilesY)
+	for row := 0; row < tilesY; row++ {
+		for col := 0; col < tilesX; col++ {
+			requests = append(requests, ImageRequest{
+				Prompt: fmt.Sprintf("%s (tile at row %d of %d, column %d of %d of a larger seamless image)",
+					prompt, row+1, tilesY, col+1, tilesX),
+				Size:           tileSize,
+				ResponseFormat: CreateImageResponseFormatB64JSON,
+			})
+		}
+	}
+	results, err := c.CreateImages(ctx, requests, tileConcurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, tilesX*tileWidth, tilesY*tileHeight))
+	for i, result := range results {
+		tile, decodeErr := c.decodeBatchResult(ctx, result)
+		if decodeErr != nil {
+			return nil, fmt.Errorf("tile %d: %w", i, decodeErr)
+		}
+		if tile.Bounds().Dx() != tileWidth || tile.Bounds().Dy() != tileHeight {
+			tile = resizeImage(tile, tileWidth, tileHeight)
+		}
+
+		origin := image.Pt((i%tilesX)*tileWidth, (i/tilesX)*tileHeight)
+		target := image.Rectangle{Min: origin, Max: origin.Add(image.Pt(tileWidth, tileHeight))}
+		draw.Draw(canvas, target, tile, tile.Bounds().Min, draw.Src)
+	}
+	return canvas, nil
+}
+
+// frameConcurrency is the number of frames GenerateFrames generates at once.
+const frameConcurrency = 4
+
+// GenerateFrames generates one image per prompt with the parameters of request and returns the
+// decoded images in prompt order, for use as the frames of an animation. dall-e models are
+// asked for b64_json output when no ResponseFormat is set. Frames are independent generations,
+// so consecutive frames are only as consistent as their prompts make them. See
+// WriteFrameSequence to save the frames for a video encoder.
+func (c *Client) GenerateFrames(ctx context.Context, prompts []string, request ImageRequest) ([]image.Image, error) {
+	request = withB64ResponseFormat(request)
+	results, err := c.CreateImagesFromPrompts(ctx, prompts, request, frameConcurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]image.Image, len(results))
+	for i, result := range results {
+		frames[i], err = c.decodeBatchResult(ctx, result)
+		if err != nil {
+			return nil, fmt.Errorf("frame %d: %w", i, err)
+		}
+	}
+	return frames, nil
+}
+
+// CreateImageUntil generates images for request until accept returns true for the decoded
+// first image of a response, making at most maxAttempts generations, for example to reject
+// images that are too dark. It returns the accepted response, or the last response with an
+// error wrapping ErrImageNotAccepted once the attempts are exhausted. dall-e models are asked
+// for b64_json output when no ResponseFormat is set. With an ImageCache on the client every
+// attempt would be served the same cached image, so use a client without one.
+func (c *Client) CreateImageUntil(
+	ctx context.Context,
+	request ImageRequest,
+	maxAttempts int,
+	accept func(image.Image) bool,
+) (response ImageResponse, err error) {
+	request = withB64ResponseFormat(request)
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		response, err = c.CreateImage(ctx, request)
+		if err != nil {
+			return response, err
+		}
+		img, decodeErr := c.decodeFirstImage(ctx, response)
+		if decodeErr != nil {
+			return response, fmt.Errorf("attempt %d: %w", attempt, decodeErr)
+		}
+		if accept(img) {
+			return response, nil
+		}
+	}
+	return response, fmt.Errorf("%w after %d attempts", ErrImageNotAccepted, maxAttempts)
+}
+
+// GenerateSprite generates a game or UI sprite for request and returns the decoded first image.
+// The model defaults to gpt-image-1, and the output format and background are set from
+// needAlpha with FormatForTransparency, transparent or opaque, so that a transparent sprite is
+// never requested in jpeg.
+func (c *Client) GenerateSprite(ctx context.Context, request ImageRequest, needAlpha bool) (image.Image, error) {
+	if request.Model == "" {
+		request.Model = CreateImageModelGptImage1
+	}
+	request.OutputFormat = FormatForTransparency(needAlpha)
+	request.Background = CreateImageBackgroundOpaque
+	if needAlpha {
+		request.Background = CreateImageBackgroundTransparent
+	}
+
+	response, err := c.CreateImage(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return c.decodeFirstImage(ctx, response)
+}
+
+// StyleReferenceOptions are the parameters of CreateWithStyleReference.
+type StyleReferenceOptions struct {
+	// Images are the images to edit in the style of the reference. When empty, the reference is
+	// sent as the single image of a plain edit, so the result is an edit of the reference itself
+	// and may keep its subject and composition despite the style-only instruction.
+	Images []io.Reader
+	// Model defaults to gpt-image-1, the only official model taking several input images.
+	Model          string
+	N              int
+	Size           string
+	Quality        string
+	ResponseFormat string
+	User           string
+}
+
+// styleReferenceInstruction tells the model how to use the reference, which is always the last image.
+const styleReferenceInstruction = "Use the last image only as a style reference: match its colors, " +
+	"lighting, medium and technique without copying its subject or composition."
+
+// CreateWithStyleReference creates an image from prompt in the style of reference, through a
+// multi-image edit that uploads the images of options followed by the reference, with the
+// prompt prefixed by an instruction to treat the last image as a style reference only.
+func (c *Client) CreateWithStyleReference(
+	ctx context.Context,
+	prompt string,
+	reference io.Reader,
+	options StyleReferenceOptions,
+) (ImageResponse, error) {
+	if reference == nil {
+		return ImageResponse{}, newValidationError(fmt.Errorf("style reference: %w", ErrNilEditImage))
+	}
+	if strings.TrimSpace(prompt) == "" {
+		return ImageResponse{}, newValidationError(ErrEmptyImagePrompt)
+	}
+	if options.Model == "" {
+		options.Model = CreateImageModelGptImage1
+	}
+
+	images := make([]io.Reader, 0, len(options.Images)+1)
+	images = append(images, options.Images...)
+	return c.CreateMultiEditImage(ctx, MultiImageEditRequest{
+		Images:         append(images, reference),
+		Prompt:         styleReferenceInstruction + "\n\n" + prompt,
+		Model:          options.Model,
+		N:              options.N,
+		Size:           options.Size,
+		ResponseFormat: options.ResponseFormat,
+		Quality:        options.Quality,
+		User:           options.User,
+	})
+}
+
+// decodeBatchResult decodes the first image of a batch result.
+func (c *Client) decodeBatchResult(ctx context.Context, result ImageBatchResult) (image.Image, error) {
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return c.decodeFirstImage(ctx, result.Response)
+}
+
+// decodeFirstImage decodes the first image of a response.
+func (c *Client) decodeFirstImage(ctx context.Context, response ImageResponse) (image.Image, error) {
+	if len(response.Data) == 0 {
+		return nil, ErrNoImageData
+	}
+	data, err := c.ResolveImageBytes(ctx, response.Data[0])
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// fetchImage downloads an image URL using the client's HTTP client.
+func (c *Client) fetchImage(ctx context.Context, imageURL string) ([]byte, error) {
+	if parsed, err := url.Parse(imageURL); err == nil {
+		var cancel context.CancelFunc
+		if ctx, cancel = c.withEndpointTimeout(ctx, parsed.Path); cancel != nil {
+			defer cancel()
+		}
+	}
+	return downloadImage(ctx, c.config.HTTPClient, imageURL)
+}
+
+// downloadImage downloads an image URL using doer.
+func downloadImage(ctx context.Context, doer HTTPDoer, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if isFailureStatusCode(resp) {
+		return nil, fmt.Errorf("%w, status code: %d, status: %s", ErrImageDownloadFailed, resp.StatusCode, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
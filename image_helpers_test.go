@@ -0,0 +1,487 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// encodeTestPNG returns the PNG encoding of a blank image with the given dimensions.
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	checks.NoErrorF(t, err, "png.Encode error")
+	return buf.Bytes()
+}
+
+// handleB64ImageEndpoint responds with one b64_json entry per image.
+func handleB64ImageEndpoint(images ...[]byte) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		res := openai.ImageResponse{Created: time.Now().Unix()}
+		for _, img := range images {
+			res.Data = append(res.Data, openai.ImageResponseDataInner{
+				B64JSON: base64.StdEncoding.EncodeToString(img),
+			})
+		}
+		resBytes, _ := json.Marshal(res)
+		fmt.Fprintln(w, string(resBytes))
+	}
+}
+
+func TestStreamImage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	imageBytes := encodeTestPNG(t, 8, 8)
+	server.RegisterHandler("/v1/images/generations", handleB64ImageEndpoint(imageBytes))
+
+	recorder := httptest.NewRecorder()
+	err := client.StreamImage(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  openai.CreateImageModelGptImage1,
+	}, recorder)
+	checks.NoError(t, err, "StreamImage error")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "image/png" {
+		t.Errorf("expected Content-Type image/png, got %q", contentType)
+	}
+	if !bytes.Equal(recorder.Body.Bytes(), imageBytes) {
+		t.Error("streamed body does not match the generated image")
+	}
+}
+
+func TestStreamImageAPIError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, `{"error":{"message":"invalid prompt","type":"invalid_request_error"}}`)
+	})
+
+	recorder := httptest.NewRecorder()
+	err := client.StreamImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"}, recorder)
+	checks.HasError(t, err, "StreamImage should return the API error")
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", recorder.Code)
+	}
+}
+
+func TestStreamImageValidationError(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	recorder := httptest.NewRecorder()
+	err := client.StreamImage(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  openai.CreateImageModelDallE3,
+		Size:   openai.CreateImageSize256x256,
+	}, recorder)
+	if openai.ErrorKindOf(err) != openai.KindValidation {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", recorder.Code)
+	}
+}
+
+func TestStreamImageNoData(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleB64ImageEndpoint())
+
+	recorder := httptest.NewRecorder()
+	err := client.StreamImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"}, recorder)
+	checks.ErrorIs(t, err, openai.ErrNoImageData, "StreamImage should fail without image data")
+	if recorder.Code != http.StatusBadGateway {
+		t.Errorf("expected status 502, got %d", recorder.Code)
+	}
+}
+
+func TestVerifyDimensions(t *testing.T) {
+	client := openai.NewClient(test.GetTestToken())
+	request := openai.ImageRequest{Size: openai.CreateImageSize256x256}
+	response := openai.ImageResponse{
+		Data: []openai.ImageResponseDataInner{
+			{B64JSON: base64.StdEncoding.EncodeToString(encodeTestPNG(t, 256, 256))},
+		},
+	}
+	err := client.VerifyDimensions(context.Background(), request, response)
+	checks.NoError(t, err, "VerifyDimensions should accept matching dimensions")
+
+	response.Data = append(response.Data, openai.ImageResponseDataInner{
+		B64JSON: base64.StdEncoding.EncodeToString(encodeTestPNG(t, 512, 256)),
+	})
+	err = client.VerifyDimensions(context.Background(), request, response)
+	checks.ErrorIs(t, err, openai.ErrImageDimensionsMismatch, "VerifyDimensions should reject mismatched dimensions")
+}
+
+func TestVerifyDimensionsNormalizesSize(t *testing.T) {
+	client := openai.NewClient(test.GetTestToken())
+	response := openai.ImageResponse{
+		Data: []openai.ImageResponseDataInner{
+			{B64JSON: base64.StdEncoding.EncodeToString(encodeTestPNG(t, 256, 256))},
+		},
+	}
+	err := client.VerifyDimensions(context.Background(), openai.ImageRequest{Size: "256 X 256"}, response)
+	checks.NoError(t, err, "VerifyDimensions should normalize the requested size")
+
+	err = client.VerifyDimensions(context.Background(), openai.ImageRequest{Size: openai.CreateImageSizeAuto}, response)
+	checks.NoError(t, err, "VerifyDimensions should not check auto sizes")
+}
+
+func TestVerifyDimensionsURL(t *testing.T) {
+	imageBytes := encodeTestPNG(t, 16, 16)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(imageBytes)
+	}))
+	defer ts.Close()
+
+	client := openai.NewClient(test.GetTestToken())
+	response := openai.ImageResponse{Data: []openai.ImageResponseDataInner{{URL: ts.URL + "/image.png"}}}
+	err := client.VerifyDimensions(context.Background(), openai.ImageRequest{Size: "16x16"}, response)
+	checks.NoError(t, err, "VerifyDimensions should download and accept matching URL images")
+
+	err = client.VerifyDimensions(context.Background(), openai.ImageRequest{Size: "32x32"}, response)
+	checks.ErrorIs(t, err, openai.ErrImageDimensionsMismatch, "VerifyDimensions should reject mismatched URL images")
+}
+
+func TestCreateAndUpscale(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	generated := encodeTestPNG(t, 256, 256)
+	upscaled := encodeTestPNG(t, 2048, 2048)
+
+	var calls []string
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "generate")
+		handleB64ImageEndpoint(generated)(w, r)
+	})
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, "edit")
+		file, _, err := r.FormFile("image")
+		if err != nil || r.FormValue("size") != "2048x2048" {
+			http.Error(w, "unexpected upscale request", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		handleB64ImageEndpoint(upscaled)(w, r)
+	})
+
+	response, err := client.CreateAndUpscale(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  "compatible-upscaler",
+		Size:   openai.CreateImageSize256x256,
+	}, "2048x2048")
+	checks.NoError(t, err, "CreateAndUpscale error")
+
+	if len(calls) != 2 || calls[0] != "generate" || calls[1] != "edit" {
+		t.Fatalf("expected a generation followed by an edit, got %v", calls)
+	}
+	err = client.VerifyDimensions(context.Background(), openai.ImageRequest{Size: "2048x2048"}, response)
+	checks.NoError(t, err, "expected the upscaled image")
+}
+
+func TestCreateAndUpscaleOfficialModel(t *testing.T) {
+	client := openai.NewClient(test.GetTestToken())
+	_, err := client.CreateAndUpscale(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  openai.CreateImageModelDallE3,
+	}, "2048x2048")
+	checks.ErrorIs(t, err, openai.ErrUpscaleUnsupported, "official models should not be upscaled")
+}
+
+func TestGenerateTiled(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var mu sync.Mutex
+	var prompts []string
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		request, err := getImageBody(r)
+		checks.NoError(t, err, "could not read request")
+		mu.Lock()
+		prompts = append(prompts, request.Prompt)
+		mu.Unlock()
+		handleB64ImageEndpoint(encodeTestPNG(t, 256, 256))(w, r)
+	})
+
+	canvas, err := client.GenerateTiled(context.Background(), "a brick wall", 2, 2, openai.CreateImageSize256x256)
+	checks.NoError(t, err, "GenerateTiled error")
+	if bounds := canvas.Bounds(); bounds.Dx() != 512 || bounds.Dy() != 512 {
+		t.Errorf("expected a 512x512 canvas, got %v", bounds)
+	}
+	if len(prompts) != 4 {
+		t.Fatalf("expected 4 tile generations, got %d", len(prompts))
+	}
+	for _, prompt := range prompts {
+		if !strings.HasPrefix(prompt, "a brick wall") {
+			t.Errorf("expected every tile prompt to start with the prompt, got %q", prompt)
+		}
+	}
+
+	_, err = client.GenerateTiled(context.Background(), "a brick wall", 0, 2, openai.CreateImageSize256x256)
+	checks.ErrorIs(t, err, openai.ErrInvalidTileGrid, "empty grids should be rejected")
+}
+
+func TestGenerateTiledBoundsConcurrency(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	tile := encodeTestPNG(t, 256, 256)
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		handleB64ImageEndpoint(tile)(w, r)
+	})
+
+	_, err := client.GenerateTiled(context.Background(), "a brick wall", 3, 3, openai.CreateImageSize256x256)
+	checks.NoError(t, err, "GenerateTiled error")
+	if maxInFlight > 4 {
+		t.Errorf("expected at most 4 tiles in flight, got %d", maxInFlight)
+	}
+}
+
+func TestGenerateFrames(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		request, err := getImageBody(r)
+		checks.NoError(t, err, "could not read request")
+		// Frame sizes tell the frames apart.
+		width := 2
+		if strings.HasPrefix(request.Prompt, "second") {
+			width = 3
+		}
+		handleB64ImageEndpoint(encodeTestPNG(t, width, 2))(w, r)
+	})
+
+	frames, err := client.GenerateFrames(context.Background(), []string{"first frame", "second frame"},
+		openai.ImageRequest{Size: openai.CreateImageSize256x256})
+	checks.NoError(t, err, "GenerateFrames error")
+	if len(frames) != 2 || frames[0].Bounds().Dx() != 2 || frames[1].Bounds().Dx() != 3 {
+		t.Fatalf("expected the two frames in prompt order, got %v", frames)
+	}
+
+	dir := t.TempDir()
+	paths, err := openai.WriteFrameSequence(frames, dir)
+	checks.NoError(t, err, "WriteFrameSequence error")
+	for i, want := range []string{"frame-00000.png", "frame-00001.png"} {
+		if paths[i] != filepath.Join(dir, want) {
+			t.Errorf("expected frame %d at %s, got %s", i, want, paths[i])
+		}
+		data, readErr := os.ReadFile(paths[i])
+		checks.NoError(t, readErr, "could not read frame")
+		if _, decodeErr := png.Decode(bytes.NewReader(data)); decodeErr != nil {
+			t.Errorf("frame %d is not a png: %v", i, decodeErr)
+		}
+	}
+}
+
+func TestCreateImageUntil(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var attempts int
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		// The second image is the first one wide enough to be accepted.
+		handleB64ImageEndpoint(encodeTestPNG(t, attempts, 1))(w, r)
+	})
+	wide := func(img image.Image) bool { return img.Bounds().Dx() >= 2 }
+
+	response, err := client.CreateImageUntil(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"}, 3, wide)
+	checks.NoError(t, err, "CreateImageUntil error")
+	if attempts != 2 || len(response.Data) != 1 {
+		t.Errorf("expected the image of the second attempt, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	never := func(image.Image) bool { return false }
+	response, err = client.CreateImageUntil(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"}, 2, never)
+	checks.ErrorIs(t, err, openai.ErrImageNotAccepted, "rejected images should fail")
+	if attempts != 2 || len(response.Data) != 1 {
+		t.Errorf("expected the last response after 2 attempts, got %d attempts", attempts)
+	}
+}
+
+func TestSniffingReader(t *testing.T) {
+	pngData := encodeTestPNG(t, 2, 2)
+	var jpegData bytes.Buffer
+	checks.NoError(t, jpeg.Encode(&jpegData, image.NewRGBA(image.Rect(0, 0, 2, 2)), nil), "jpeg.Encode error")
+	webpData := []byte("RIFF\x1a\x00\x00\x00WEBPVP8 \x0e\x00\x00\x00")
+
+	cases := []struct {
+		name        string
+		data        []byte
+		contentType string
+	}{
+		{"png", pngData, "image/png"},
+		{"jpeg", jpegData.Bytes(), "image/jpeg"},
+		{"webp", webpData, "image/webp"},
+		{"unknown", []byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			readers := map[string]io.Reader{
+				"seekable":     bytes.NewReader(tc.data),
+				"non-seekable": io.MultiReader(bytes.NewReader(tc.data)),
+			}
+			for kind, r := range readers {
+				replay, contentType := openai.SniffingReader(r)
+				if contentType != tc.contentType {
+					t.Errorf("%s: expected content type %q, got %q", kind, tc.contentType, contentType)
+				}
+				got, err := io.ReadAll(replay)
+				checks.NoError(t, err, "ReadAll error")
+				if !bytes.Equal(got, tc.data) {
+					t.Errorf("%s: replayed content does not match the input", kind)
+				}
+			}
+		})
+	}
+
+	if r, contentType := openai.SniffingReader(nil); r != nil || contentType != "" {
+		t.Errorf("expected nil reader and empty type, got %v %q", r, contentType)
+	}
+}
+
+func TestCreateWithStyleReference(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	reference := encodeTestPNG(t, 4, 4)
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, "could not parse form", http.StatusBadRequest)
+			return
+		}
+		files := r.MultipartForm.File["image[]"]
+		if len(files) != 2 {
+			http.Error(w, "expected two images", http.StatusBadRequest)
+			return
+		}
+		part, err := files[1].Open()
+		if err != nil {
+			http.Error(w, "could not open reference", http.StatusBadRequest)
+			return
+		}
+		defer part.Close()
+		data, _ := io.ReadAll(part)
+		if !bytes.Equal(data, reference) || files[1].Header.Get("Content-Type") != "image/png" {
+			http.Error(w, "the reference is not the last png image", http.StatusBadRequest)
+			return
+		}
+		if !strings.HasSuffix(r.FormValue("prompt"), "a lighthouse") ||
+			!strings.Contains(r.FormValue("prompt"), "style reference") {
+			http.Error(w, "unexpected prompt", http.StatusBadRequest)
+			return
+		}
+		handleB64ImageEndpoint(encodeTestPNG(t, 4, 4))(w, r)
+	})
+
+	_, err := client.CreateWithStyleReference(context.Background(), "a lighthouse", bytes.NewReader(reference),
+		openai.StyleReferenceOptions{Images: []io.Reader{bytes.NewReader(encodeTestPNG(t, 8, 8))}, N: 1})
+	checks.NoError(t, err, "CreateWithStyleReference error")
+
+	_, err = client.CreateWithStyleReference(context.Background(), "a lighthouse", nil, openai.StyleReferenceOptions{})
+	checks.ErrorIs(t, err, openai.ErrNilEditImage, "expected a nil reference to be rejected")
+}
+
+func TestGenerateSprite(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var sent openai.ImageRequest
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&sent); err != nil {
+			http.Error(w, "could not read request", http.StatusBadRequest)
+			return
+		}
+		handleB64ImageEndpoint(encodeTestPNG(t, 4, 4))(w, r)
+	})
+
+	cases := []struct {
+		needAlpha  bool
+		format     string
+		background string
+	}{
+		{true, openai.CreateImageOutputFormatPNG, openai.CreateImageBackgroundTransparent},
+		{false, openai.CreateImageOutputFormatJPEG, openai.CreateImageBackgroundOpaque},
+	}
+	for _, tc := range cases {
+		sprite, err := client.GenerateSprite(context.Background(), openai.ImageRequest{
+			Prompt:       "a pixel art knight",
+			OutputFormat: openai.CreateImageOutputFormatWEBP,
+		}, tc.needAlpha)
+		checks.NoError(t, err, "GenerateSprite error")
+		if sprite.Bounds().Dx() != 4 {
+			t.Errorf("expected the decoded sprite, got bounds %v", sprite.Bounds())
+		}
+		if sent.Model != openai.CreateImageModelGptImage1 || sent.OutputFormat != tc.format ||
+			sent.Background != tc.background {
+			t.Errorf("needAlpha %v: unexpected request %+v", tc.needAlpha, sent)
+		}
+	}
+}
+
+func TestCreateWithStyleReferenceSendsOptions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	var form map[string][]string
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, "could not parse form", http.StatusBadRequest)
+			return
+		}
+		form = r.MultipartForm.Value
+		handleB64ImageEndpoint(encodeTestPNG(t, 4, 4))(w, r)
+	})
+
+	_, err := client.CreateWithStyleReference(context.Background(), "a lighthouse",
+		bytes.NewReader(encodeTestPNG(t, 4, 4)), openai.StyleReferenceOptions{
+			Images:  []io.Reader{bytes.NewReader(encodeTestPNG(t, 8, 8))},
+			N:       1,
+			Quality: openai.CreateImageQualityHigh,
+			User:    "user-1",
+		})
+	checks.NoError(t, err, "CreateWithStyleReference error")
+
+	expected := map[string]string{
+		"model":   openai.CreateImageModelGptImage1,
+		"quality": openai.CreateImageQualityHigh,
+		"user":    "user-1",
+	}
+	for name, want := range expected {
+		if got := form[name]; len(got) != 1 || got[0] != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+//go:build go1.23
+
+package openai
+
+import "iter"
+
+// Iter returns an iterator over the entries of the response with their index, so that
+// callers can process the images of a large b64_json response one at a time and stop early.
+// Entries are not decoded; see ImageResult for decoding helpers.
+func (r ImageResponse) Iter() iter.Seq2[int, ImageResponseDataInner] {
+	return func(yield func(int, ImageResponseDataInner) bool) {
+		for i, entry := range r.Data {
+			if !yield(i, entry) {
+				return
+			}
+		}
+	}
+}
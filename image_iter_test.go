@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package openai_test
+
+import (
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestImageResponseIter(t *testing.T) {
+	response := openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+		{URL: "https://example.com/0.png"},
+		{URL: "https://example.com/1.png"},
+		{URL: "https://example.com/2.png"},
+	}}
+
+	var urls []string
+	for i, entry := range response.Iter() {
+		if i == 2 {
+			break
+		}
+		urls = append(urls, entry.URL)
+	}
+	if len(urls) != 2 || urls[1] != "https://example.com/1.png" {
+		t.Errorf("expected the first two entries before breaking, got %v", urls)
+	}
+}
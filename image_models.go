@@ -0,0 +1,153 @@
+package openai
+
+// ImageModelInfo describes the capabilities of an image model.
+type ImageModelInfo struct {
+	Model                string
+	Sizes                []string
+	Qualities            []string
+	OutputFormats        []string
+	MaxN                 int
+	SupportsEdits        bool
+	SupportsVariations   bool
+	SupportsTransparency bool
+}
+
+// imageModels is the static capability table for the image models defined by the OpenAI API.
+var imageModels = []ImageModelInfo{
+	{
+		Model: CreateImageModelDallE2,
+		Sizes: []string{
+			CreateImageSize256x256,
+			CreateImageSize512x512,
+			CreateImageSize1024x1024,
+		},
+		Qualities:          []string{CreateImageQualityStandard},
+		OutputFormats:      []string{CreateImageOutputFormatPNG},
+		MaxN:               10,
+		SupportsEdits:      true,
+		SupportsVariations: true,
+	},
+	{
+		Model: CreateImageModelDallE3,
+		Sizes: []string{
+			CreateImageSize1024x1024,
+			CreateImageSize1792x1024,
+			CreateImageSize1024x1792,
+		},
+		Qualities:     []string{CreateImageQualityHD, CreateImageQualityStandard},
+		OutputFormats: []string{CreateImageOutputFormatPNG},
+		MaxN:          1,
+	},
+	{
+		Model: CreateImageModelGptImage1,
+		Sizes: []string{
+			CreateImageSize1024x1024,
+			CreateImageSize1536x1024,
+			CreateImageSize1024x1536,
+			CreateImageSizeAuto,
+		},
+		Qualities: []string{
+			CreateImageQualityHigh,
+			CreateImageQualityMedium,
+			CreateImageQualityLow,
+			CreateImageQualityAuto,
+		},
+		OutputFormats: []string{
+			CreateImageOutputFormatPNG,
+			CreateImageOutputFormatJPEG,
+			CreateImageOutputFormatWEBP,
+		},
+		MaxN:                 10,
+		SupportsEdits:        true,
+		SupportsTransparency: true,
+	},
+}
+
+// SupportedImageModels returns the image models known to the client along with their capabilities.
+// The returned slice is a copy and can be modified freely.
+func SupportedImageModels() []ImageModelInfo {
+	models := make([]ImageModelInfo, 0, len(imageModels))
+	for _, info := range imageModels {
+		models = append(models, info.clone())
+	}
+	return models
+}
+
+// lookupImageModel returns the capabilities of a known image model.
+func lookupImageModel(model string) (ImageModelInfo, bool) {
+	for _, info := range imageModels {
+		if info.Model == model {
+			return info, true
+		}
+	}
+	return ImageModelInfo{}, false
+}
+
+// SupportsSize reports whether the model accepts the given size.
+func (m ImageModelInfo) SupportsSize(size string) bool {
+	return containsString(m.Sizes, size)
+}
+
+// SupportsQuality reports whether the model accepts the given quality.
+func (m ImageModelInfo) SupportsQuality(quality string) bool {
+	return containsString(m.Qualities, quality)
+}
+
+// SupportsOutputFormat reports whether the model can return images in the given format.
+func (m ImageModelInfo) SupportsOutputFormat(format string) bool {
+	return containsString(m.OutputFormats, format)
+}
+
+func (m ImageModelInfo) clone() ImageModelInfo {
+	m.Sizes = append([]string(nil), m.Sizes...)
+	m.Qualities = append([]string(nil), m.Qualities...)
+	m.OutputFormats = append([]string(nil), m.OutputFormats...)
+	return m
+}
+
+func containsString(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+	return false
+}
+
+// Use cases understood by RecommendOutputFormat.
+const (
+	ImageUseCaseWeb       = "web"
+	ImageUseCasePrint     = "print"
+	ImageUseCaseThumbnail = "thumbnail"
+)
+
+const (
+	webOutputCompression       = 80
+	thumbnailOutputCompression = 60
+)
+
+// RecommendOutputFormat returns a gpt-image-1 output format and compression suited to the
+// use case: compressed webp for the web, lossless png for print and small jpeg files for
+// thumbnails. Unknown use cases get png, the API default.
+func RecommendOutputFormat(useCase string) (format string, compression int) {
+	switch useCase {
+	case ImageUseCaseWeb:
+		return CreateImageOutputFormatWEBP, webOutputCompression
+	case ImageUseCaseThumbnail:
+		return CreateImageOutputFormatJPEG, thumbnailOutputCompression
+	case ImageUseCasePrint:
+		return CreateImageOutputFormatPNG, 0
+	default:
+		return CreateImageOutputFormatPNG, 0
+	}
+}
+
+// FormatForTransparency returns a gpt-image-1 output format that fits whether the image needs
+// an alpha channel: png when it does, since jpeg cannot store transparency, and the smaller
+// jpeg otherwise.
+func FormatForTransparency(needAlpha bool) string {
+	if needAlpha {
+		return CreateImageOutputFormatPNG
+	}
+	return CreateImageOutputFormatJPEG
+}
@@ -0,0 +1,98 @@
+package openai_test
+
+import (
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func TestSupportedImageModels(t *testing.T) {
+	models := map[string]openai.ImageModelInfo{}
+	for _, info := range openai.SupportedImageModels() {
+		models[info.Model] = info
+	}
+
+	dallE2, ok := models[openai.CreateImageModelDallE2]
+	if !ok {
+		t.Fatalf("expected %s to be supported", openai.CreateImageModelDallE2)
+	}
+	if !dallE2.SupportsVariations || !dallE2.SupportsEdits {
+		t.Errorf("expected %s to support edits and variations", openai.CreateImageModelDallE2)
+	}
+	if !dallE2.SupportsSize(openai.CreateImageSize256x256) {
+		t.Errorf("expected %s to support %s", openai.CreateImageModelDallE2, openai.CreateImageSize256x256)
+	}
+
+	dallE3, ok := models[openai.CreateImageModelDallE3]
+	if !ok {
+		t.Fatalf("expected %s to be supported", openai.CreateImageModelDallE3)
+	}
+	if dallE3.SupportsEdits || dallE3.SupportsVariations {
+		t.Errorf("expected %s to support neither edits nor variations", openai.CreateImageModelDallE3)
+	}
+	if dallE3.MaxN != 1 {
+		t.Errorf("expected %s MaxN to be 1, got %d", openai.CreateImageModelDallE3, dallE3.MaxN)
+	}
+	if !dallE3.SupportsQuality(openai.CreateImageQualityHD) {
+		t.Errorf("expected %s to support %s quality", openai.CreateImageModelDallE3, openai.CreateImageQualityHD)
+	}
+
+	gptImage1, ok := models[openai.CreateImageModelGptImage1]
+	if !ok {
+		t.Fatalf("expected %s to be supported", openai.CreateImageModelGptImage1)
+	}
+	if !gptImage1.SupportsTransparency {
+		t.Errorf("expected %s to support transparency", openai.CreateImageModelGptImage1)
+	}
+	if gptImage1.SupportsSize(openai.CreateImageSize1792x1024) {
+		t.Errorf("expected %s not to support %s", openai.CreateImageModelGptImage1, openai.CreateImageSize1792x1024)
+	}
+	if gptImage1.SupportsQuality(openai.CreateImageQualityHD) {
+		t.Errorf("expected %s not to support %s quality", openai.CreateImageModelGptImage1, openai.CreateImageQualityHD)
+	}
+}
+
+func TestSupportedImageModelsReturnsCopy(t *testing.T) {
+	models := openai.SupportedImageModels()
+	models[0].Sizes[0] = "1x1"
+
+	if openai.SupportedImageModels()[0].Sizes[0] == "1x1" {
+		t.Error("SupportedImageModels should not expose the internal table")
+	}
+}
+
+func TestRecommendOutputFormat(t *testing.T) {
+	cases := []struct {
+		useCase     string
+		format      string
+		compression int
+	}{
+		{openai.ImageUseCaseWeb, openai.CreateImageOutputFormatWEBP, 80},
+		{openai.ImageUseCasePrint, openai.CreateImageOutputFormatPNG, 0},
+		{openai.ImageUseCaseThumbnail, openai.CreateImageOutputFormatJPEG, 60},
+		{"unknown", openai.CreateImageOutputFormatPNG, 0},
+	}
+	for _, tc := range cases {
+		format, compression := openai.RecommendOutputFormat(tc.useCase)
+		if format != tc.format || compression != tc.compression {
+			t.Errorf("%s: expected %s/%d, got %s/%d", tc.useCase, tc.format, tc.compression, format, compression)
+		}
+		request := openai.ImageRequest{
+			Model:             openai.CreateImageModelGptImage1,
+			OutputFormat:      format,
+			OutputCompression: compression,
+		}
+		if err := request.Validate(); err != nil {
+			t.Errorf("%s: recommended settings should be valid, got %v", tc.useCase, err)
+		}
+	}
+}
+
+func TestFormatForTransparency(t *testing.T) {
+	if got := openai.FormatForTransparency(true); got != openai.CreateImageOutputFormatPNG {
+		t.Errorf("expected png for transparent images, got %s", got)
+	}
+	if got := openai.FormatForTransparency(false); got != openai.CreateImageOutputFormatJPEG {
+		t.Errorf("expected jpeg for opaque images, got %s", got)
+	}
+}
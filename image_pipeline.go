@@ -0,0 +1,63 @@
+package openai
+
+import (
+	"context"
+	"time"
+)
+
+// ImageHandler generates images for a request.
+type ImageHandler func(ctx context.Context, request ImageRequest) (ImageResponse, error)
+
+// ImageMiddleware wraps an ImageHandler, for example to inspect or modify the request and response.
+type ImageMiddleware func(next ImageHandler) ImageHandler
+
+// ImagePipeline returns a handler that passes requests through middlewares before calling CreateImage.
+// The first middleware is the outermost one: it sees the request first and the response last.
+func (c *Client) ImagePipeline(middlewares ...ImageMiddleware) ImageHandler {
+	handler := ImageHandler(c.CreateImage)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// LoggingImageMiddleware logs every request with its outcome and duration using logf.
+func LoggingImageMiddleware(logf func(format string, args ...any)) ImageMiddleware {
+	return func(next ImageHandler) ImageHandler {
+		return func(ctx context.Context, request ImageRequest) (ImageResponse, error) {
+			start := time.Now()
+			response, err := next(ctx, request)
+			if err != nil {
+				logf("image generation failed: model=%q size=%q n=%d duration=%s error=%v",
+					request.Model, request.Size, request.N, time.Since(start), err)
+				return response, err
+			}
+			logf("image generation succeeded: model=%q size=%q n=%d duration=%s images=%d",
+				request.Model, request.Size, request.N, time.Since(start), len(response.Data))
+			return response, nil
+		}
+	}
+}
+
+// RetryImageMiddleware retries failed generations up to attempts times in total, waiting backoff
+// before the first retry and doubling it on every further one, up to 5 minutes or backoff if
+// longer. Validation errors are not retried.
+func RetryImageMiddleware(attempts int, backoff time.Duration) ImageMiddleware {
+	return retryImageMiddleware(realClock{}, attempts, backoff)
+}
+
+// retryImageMiddleware is RetryImageMiddleware waiting on clk.
+func retryImageMiddleware(clk clock, attempts int, backoff time.Duration) ImageMiddleware {
+	return func(next ImageHandler) ImageHandler {
+		return func(ctx context.Context, request ImageRequest) (ImageResponse, error) {
+			response, err := next(ctx, request)
+			for attempt := 1; attempt < attempts && err != nil && ErrorKindOf(err) != KindValidation; attempt++ {
+				if sleepErr := sleepContext(ctx, clk, retryDelay(backoff, attempt)); sleepErr != nil {
+					return response, sleepErr
+				}
+				response, err = next(ctx, request)
+			}
+			return response, err
+		}
+	}
+}
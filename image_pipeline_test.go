@@ -0,0 +1,80 @@
+package openai_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// recordingMiddleware appends name to events around the call and tags the request and response.
+func recordingMiddleware(name string, events *[]string) openai.ImageMiddleware {
+	return func(next openai.ImageHandler) openai.ImageHandler {
+		return func(ctx context.Context, request openai.ImageRequest) (openai.ImageResponse, error) {
+			*events = append(*events, name+" request: "+request.Prompt)
+			request.Prompt += " " + name
+			response, err := next(ctx, request)
+			*events = append(*events, fmt.Sprintf("%s response: %d images", name, len(response.Data)))
+			return response, err
+		}
+	}
+}
+
+func TestImagePipelineOrder(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var sentPrompt string
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		request, err := getImageBody(r)
+		checks.NoError(t, err, "could not read request")
+		sentPrompt = request.Prompt
+		handleB64ImageEndpoint([]byte("image"))(w, r)
+	})
+
+	var events []string
+	pipeline := client.ImagePipeline(
+		recordingMiddleware("outer", &events),
+		recordingMiddleware("inner", &events),
+	)
+	_, err := pipeline(context.Background(), openai.ImageRequest{Prompt: "cat"})
+	checks.NoError(t, err, "pipeline error")
+
+	expected := []string{
+		"outer request: cat",
+		"inner request: cat outer",
+		"inner response: 1 images",
+		"outer response: 1 images",
+	}
+	if strings.Join(events, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected middleware events: %v", events)
+	}
+	if sentPrompt != "cat outer inner" {
+		t.Errorf("expected middlewares to modify the request, server got %q", sentPrompt)
+	}
+}
+
+func TestImagePipelineBuiltins(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(1))
+
+	var logs []string
+	pipeline := client.ImagePipeline(
+		openai.LoggingImageMiddleware(func(format string, args ...any) {
+			logs = append(logs, fmt.Sprintf(format, args...))
+		}),
+		openai.RetryImageMiddleware(2, time.Millisecond),
+	)
+	_, err := pipeline(context.Background(), openai.ImageRequest{Prompt: "cat"})
+	checks.NoError(t, err, "the retry middleware should recover from a single failure")
+
+	if len(logs) != 1 || !strings.Contains(logs[0], "succeeded") {
+		t.Errorf("expected a single success log entry, got %v", logs)
+	}
+}
@@ -0,0 +1,83 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"net/http"
+	"os"
+)
+
+// requestIDHeader is the response header carrying the ID the API assigned to a request.
+const requestIDHeader = "X-Request-Id"
+
+// ImageResults is the common surface of the responses of every image endpoint, for code
+// that handles generations, edits and variations alike. ImageResponse implements it.
+type ImageResults interface {
+	Images() []ImageResult
+	TokenUsage() ImageResponseUsage
+	RequestID() string
+}
+
+var _ ImageResults = ImageResponse{}
+
+// ImageResult is a single image of a response, with helpers to get at its content
+// whether it was returned as b64_json or as a URL.
+type ImageResult struct {
+	ImageResponseDataInner
+	// Index is the position of the image in the response.
+	Index int
+}
+
+// Images returns the images of the response in order.
+func (r ImageResponse) Images() []ImageResult {
+	results := make([]ImageResult, len(r.Data))
+	for i, entry := range r.Data {
+		results[i] = ImageResult{ImageResponseDataInner: entry, Index: i}
+	}
+	return results
+}
+
+// TokenUsage returns the token usage of the response, reported by gpt-image-1 only.
+func (r ImageResponse) TokenUsage() ImageResponseUsage {
+	return r.Usage
+}
+
+// RequestID returns the ID the API assigned to the request, for support requests and logs,
+// or an empty string if the server did not report one.
+func (r ImageResponse) RequestID() string {
+	return r.Header().Get(requestIDHeader)
+}
+
+// Bytes returns the encoded image, decoding b64_json or downloading the URL. URLs are
+// downloaded with http.DefaultClient; use Client.ResolveImageBytes to download with the
+// HTTP client of a client instead.
+func (r ImageResult) Bytes(ctx context.Context) ([]byte, error) {
+	if r.B64JSON != "" {
+		return base64.StdEncoding.DecodeString(r.B64JSON)
+	}
+	if r.URL != "" {
+		return downloadImage(ctx, http.DefaultClient, r.URL)
+	}
+	return nil, ErrNoImageData
+}
+
+// Decode returns the decoded image.
+func (r ImageResult) Decode(ctx context.Context) (image.Image, error) {
+	data, err := r.Bytes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// Save writes the encoded image to path.
+func (r ImageResult) Save(ctx context.Context, path string) error {
+	data, err := r.Bytes(ctx)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,59 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestImageResults(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	b64Image := encodeTestPNG(t, 3, 2)
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-Id", "req_123")
+		handleB64ImageEndpoint(b64Image)(w, r)
+	})
+	urlImage := encodeTestPNG(t, 1, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(urlImage)
+	}))
+	defer ts.Close()
+
+	response, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+	response.Data = append(response.Data, openai.ImageResponseDataInner{URL: ts.URL + "/image.png"})
+
+	var results openai.ImageResults = response
+	if id := results.RequestID(); id != "req_123" {
+		t.Errorf("expected request ID req_123, got %q", id)
+	}
+	images := results.Images()
+	if len(images) != 2 || images[1].Index != 1 {
+		t.Fatalf("expected two indexed images, got %+v", images)
+	}
+
+	img, err := images[0].Decode(context.Background())
+	checks.NoError(t, err, "Decode error")
+	if img.Bounds().Dx() != 3 || img.Bounds().Dy() != 2 {
+		t.Errorf("expected a 3x2 image, got %v", img.Bounds())
+	}
+
+	path := filepath.Join(t.TempDir(), "image.png")
+	checks.NoError(t, images[1].Save(context.Background(), path), "Save error")
+	saved, err := os.ReadFile(path)
+	checks.NoError(t, err, "ReadFile error")
+	if !bytes.Equal(saved, urlImage) {
+		t.Error("expected the downloaded image to be saved")
+	}
+
+	_, err = openai.ImageResult{}.Bytes(context.Background())
+	checks.ErrorIs(t, err, openai.ErrNoImageData, "empty results should have no bytes")
+}
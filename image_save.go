@@ -0,0 +1,346 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var ErrChecksumRequiresDownload = errors.New("url image entries must be downloaded to compute a checksum")
+
+// SaveImageOptions controls how SaveImages names the files it writes.
+type SaveImageOptions struct {
+	// Prefix starts every file name, defaults to "image".
+	Prefix string
+	// CorrelationID, if set, is embedded in every file name after the prefix so that saved
+	// files can be tied back to the request that produced them. Characters other than
+	// letters, digits, '-', '_' and '.' are replaced with '_'.
+	CorrelationID string
+	// Format is the file extension, such as "png" or "jpeg". When empty it is detected
+	// from the image bytes.
+	Format string
+}
+
+// WritableFS is the storage the save helpers write images to, such as a directory, memory in
+// tests or an adapter to an object store.
+type WritableFS interface {
+	// Create creates or truncates the named file. Names are slash-separated and unrooted,
+	// as accepted by fs.ValidPath.
+	Create(name string) (io.WriteCloser, error)
+}
+
+// DirFS returns a WritableFS that creates files in the directory dir.
+func DirFS(dir string) WritableFS {
+	return dirFS(dir)
+}
+
+type dirFS string
+
+func (dir dirFS) Create(name string) (io.WriteCloser, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	return os.OpenFile(filepath.Join(string(dir), filepath.FromSlash(name)), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+}
+
+// writeFSFile writes data to the named file of fsys.
+func writeFSFile(fsys WritableFS, name string, data []byte) error {
+	w, err := fsys.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// SaveImages writes every image of the response to dir, downloading url entries and decoding
+// b64_json ones, and returns the written paths aligned by index with response.Data. Files are
+// named "<prefix>[-<correlation id>]-<index>.<format>".
+func (c *Client) SaveImages(
+	ctx context.Context,
+	response ImageResponse,
+	dir string,
+	options SaveImageOptions,
+) ([]string, error) {
+	names, err := c.SaveImagesFS(ctx, response, DirFS(dir), options)
+	return joinPaths(dir, names), err
+}
+
+// SaveImagesFS is like SaveImages but writes to fsys and returns the names of the written files.
+func (c *Client) SaveImagesFS(
+	ctx context.Context,
+	response ImageResponse,
+	fsys WritableFS,
+	options SaveImageOptions,
+) ([]string, error) {
+	base := options.Prefix
+	if base == "" {
+		base = "image"
+	}
+	if id := sanitizeFileNamePart(options.CorrelationID); id != "" {
+		base += "-" + id
+	}
+
+	names := make([]string, 0, len(response.Data))
+	for i, entry := range response.Data {
+		data, err := c.ResolveImageBytes(ctx, entry)
+		if err != nil {
+			return names, fmt.Errorf("image %d: %w", i, err)
+		}
+
+		format := options.Format
+		if format == "" {
+			format = detectImageFormat(data)
+		}
+		name := fmt.Sprintf("%s-%d.%s", base, i, format)
+		err = writeFSFile(fsys, name, data)
+		if err != nil {
+			return names, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// joinPaths returns the paths of the named files of dir.
+func joinPaths(dir string, names []string) []string {
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(dir, filepath.FromSlash(name))
+	}
+	return paths
+}
+
+// WriteFrameSequence writes frames to dir as png files numbered from zero with five digits,
+// "frame-00000.png", "frame-00001.png" and so on, and returns the written paths. The sequence
+// can be encoded with ffmpeg, for example "ffmpeg -framerate 12 -i frame-%05d.png out.mp4".
+func WriteFrameSequence(frames []image.Image, dir string) ([]string, error) {
+	names, err := WriteFrameSequenceFS(frames, DirFS(dir))
+	return joinPaths(dir, names), err
+}
+
+// WriteFrameSequenceFS is like WriteFrameSequence but writes to fsys and returns the names of
+// the written files.
+func WriteFrameSequenceFS(frames []image.Image, fsys WritableFS) ([]string, error) {
+	names := make([]string, 0, len(frames))
+	for i, frame := range frames {
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, frame); err != nil {
+			return names, fmt.Errorf("frame %d: %w", i, err)
+		}
+		name := fmt.Sprintf("frame-%05d.png", i)
+		if err := writeFSFile(fsys, name, buf.Bytes()); err != nil {
+			return names, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// ResolveImageBytes returns the image of a response entry, decoding b64_json or downloading the url.
+func (c *Client) ResolveImageBytes(ctx context.Context, entry ImageResponseDataInner) ([]byte, error) {
+	if entry.B64JSON != "" {
+		return base64.StdEncoding.DecodeString(entry.B64JSON)
+	}
+	if entry.URL != "" {
+		return c.fetchImage(ctx, entry.URL)
+	}
+	return nil, ErrNoImageData
+}
+
+// DownloadAll resolves every image of the response, downloading url entries with at most
+// concurrency downloads at once and decoding b64_json entries locally. The images are aligned
+// by index with response.Data. It stops starting downloads once ctx is done or one has failed,
+// and returns the first error.
+func (c *Client) DownloadAll(ctx context.Context, response ImageResponse, concurrency int) ([][]byte, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	images := make([][]byte, len(response.Data))
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	failed := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return firstErr != nil
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, entry := range response.Data {
+		if entry.URL == "" || entry.B64JSON != "" {
+			data, err := c.ResolveImageBytes(ctx, entry)
+			if err != nil {
+				setErr(fmt.Errorf("image %d: %w", i, err))
+				break
+			}
+			images[i] = data
+			continue
+		}
+
+		sem <- struct{}{}
+		if err := ctx.Err(); err != nil {
+			<-sem
+			setErr(err)
+			break
+		}
+		if failed() {
+			<-sem
+			break
+		}
+		wg.Add(1)
+		go func(i int, entry ImageResponseDataInner) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			data, err := c.ResolveImageBytes(ctx, entry)
+			if err != nil {
+				setErr(fmt.Errorf("image %d: %w", i, err))
+				return
+			}
+			images[i] = data
+		}(i, entry)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return images, nil
+}
+
+// defaultDownloadConcurrency is the number of concurrent downloads of DownloadImages.
+const defaultDownloadConcurrency = 4
+
+// DownloadImage fetches the image of a url entry with the HTTP client of the client,
+// bounded by ctx. A failure status is returned as an error wrapping ErrImageDownloadFailed
+// with the status code. Entries without a url return ErrNoImageData.
+func (c *Client) DownloadImage(ctx context.Context, d ImageResponseDataInner) ([]byte, error) {
+	if d.URL == "" {
+		return nil, fmt.Errorf("%w: the entry has no url", ErrNoImageData)
+	}
+	return c.fetchImage(ctx, d.URL)
+}
+
+// DownloadImages resolves every image of the response like DownloadAll, downloading up to
+// four url entries at once.
+func (c *Client) DownloadImages(ctx context.Context, response ImageResponse) ([][]byte, error) {
+	return c.DownloadAll(ctx, response, defaultDownloadConcurrency)
+}
+
+// SHA256 returns the hex-encoded SHA-256 checksum of the decoded b64_json image. Entries that
+// only have a url return ErrChecksumRequiresDownload; use Client.ImageChecksums for those.
+func (d ImageResponseDataInner) SHA256() (string, error) {
+	if d.B64JSON == "" {
+		if d.URL != "" {
+			return "", ErrChecksumRequiresDownload
+		}
+		return "", ErrNoImageData
+	}
+	data, err := base64.StdEncoding.DecodeString(d.B64JSON)
+	if err != nil {
+		return "", err
+	}
+	return sha256Hex(data), nil
+}
+
+// DecodeImage decodes the b64_json image and returns it along with its format name, as
+// registered with image.RegisterFormat, such as "png" or "jpeg". Decoding needs the decoder
+// of the format to be registered: this package registers png, jpeg and gif, while webp
+// output needs a blank import of golang.org/x/image/webp. Entries without b64_json, such
+// as url entries, return an error wrapping ErrNoImageData.
+func (d ImageResponseDataInner) DecodeImage() (image.Image, string, error) {
+	if d.B64JSON == "" {
+		if d.URL != "" {
+			return nil, "", fmt.Errorf("%w: the image is at a url, download it first", ErrNoImageData)
+		}
+		return nil, "", ErrNoImageData
+	}
+	data, err := base64.StdEncoding.DecodeString(d.B64JSON)
+	if err != nil {
+		return nil, "", err
+	}
+	return image.Decode(bytes.NewReader(data))
+}
+
+// SHA256 returns the checksums of every image of the response, aligned by index with Data.
+func (r ImageResponse) SHA256() ([]string, error) {
+	checksums := make([]string, len(r.Data))
+	for i, entry := range r.Data {
+		checksum, err := entry.SHA256()
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+		checksums[i] = checksum
+	}
+	return checksums, nil
+}
+
+// ImageChecksums is like ImageResponse.SHA256 but downloads url entries to hash them.
+func (c *Client) ImageChecksums(ctx context.Context, response ImageResponse) ([]string, error) {
+	checksums := make([]string, len(response.Data))
+	for i, entry := range response.Data {
+		data, err := c.ResolveImageBytes(ctx, entry)
+		if err != nil {
+			return nil, fmt.Errorf("image %d: %w", i, err)
+		}
+		checksums[i] = sha256Hex(data)
+	}
+	return checksums, nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// detectImageFormat returns the output format name matching the image bytes, defaulting to png.
+func detectImageFormat(data []byte) string {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return CreateImageOutputFormatJPEG
+	case "image/webp":
+		return CreateImageOutputFormatWEBP
+	default:
+		return CreateImageOutputFormatPNG
+	}
+}
+
+// sanitizeFileNamePart makes s safe to embed in a file name.
+func sanitizeFileNamePart(s string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, s), ".")
+}
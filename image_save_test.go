@@ -0,0 +1,230 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestSaveImages(t *testing.T) {
+	urlImage := encodeTestPNG(t, 2, 2)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(urlImage)
+	}))
+	defer ts.Close()
+
+	client := openai.NewClient(test.GetTestToken())
+	b64Image := encodeTestPNG(t, 4, 4)
+	response := openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+		{B64JSON: base64.StdEncoding.EncodeToString(b64Image)},
+		{URL: ts.URL + "/image.png"},
+	}}
+
+	dir := t.TempDir()
+	paths, err := client.SaveImages(context.Background(), response, dir, openai.SaveImageOptions{
+		Prefix:        "cat",
+		CorrelationID: "req/42 abc",
+	})
+	checks.NoError(t, err, "SaveImages error")
+
+	expected := []string{filepath.Join(dir, "cat-req_42_abc-0.png"), filepath.Join(dir, "cat-req_42_abc-1.png")}
+	if strings.Join(paths, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected paths %v, got %v", expected, paths)
+	}
+	for i, want := range [][]byte{b64Image, urlImage} {
+		got, readErr := os.ReadFile(paths[i])
+		checks.NoError(t, readErr, "ReadFile error")
+		if !bytes.Equal(got, want) {
+			t.Errorf("image %d: unexpected content", i)
+		}
+	}
+}
+
+func TestDownloadAll(t *testing.T) {
+	tracker := &concurrencyTracker{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tracker.enter()
+		defer tracker.leave()
+		time.Sleep(10 * time.Millisecond)
+		_, _ = w.Write([]byte("url " + r.URL.Path))
+	}))
+	defer ts.Close()
+
+	client := openai.NewClient(test.GetTestToken())
+	response := openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+		{URL: ts.URL + "/0"},
+		{B64JSON: base64.StdEncoding.EncodeToString([]byte("b64 1"))},
+		{URL: ts.URL + "/2"},
+		{URL: ts.URL + "/3"},
+		{URL: ts.URL + "/4"},
+	}}
+	images, err := client.DownloadAll(context.Background(), response, 2)
+	checks.NoError(t, err, "DownloadAll error")
+
+	expected := []string{"url /0", "b64 1", "url /2", "url /3", "url /4"}
+	for i, want := range expected {
+		if string(images[i]) != want {
+			t.Errorf("image %d: expected %q, got %q", i, want, images[i])
+		}
+	}
+	if tracker.peak > 2 {
+		t.Errorf("expected at most 2 concurrent downloads, got %d", tracker.peak)
+	}
+}
+
+func TestDownloadAllCanceledContext(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("image"))
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client := openai.NewClient(test.GetTestToken())
+	_, err := client.DownloadAll(ctx, openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+		{URL: ts.URL + "/0"},
+	}}, 1)
+	checks.ErrorIs(t, err, context.Canceled, "DownloadAll should return the context error")
+}
+
+func TestImageSHA256(t *testing.T) {
+	// SHA-256 of "hello world".
+	const helloSHA256 = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+	entry := openai.ImageResponseDataInner{B64JSON: base64.StdEncoding.EncodeToString([]byte("hello world"))}
+	checksum, err := entry.SHA256()
+	checks.NoError(t, err, "SHA256 error")
+	if checksum != helloSHA256 {
+		t.Errorf("unexpected checksum %s", checksum)
+	}
+
+	response := openai.ImageResponse{Data: []openai.ImageResponseDataInner{entry, entry}}
+	checksums, err := response.SHA256()
+	checks.NoError(t, err, "response SHA256 error")
+	if len(checksums) != 2 || checksums[0] != helloSHA256 || checksums[1] != helloSHA256 {
+		t.Errorf("unexpected checksums %v", checksums)
+	}
+
+	_, err = openai.ImageResponseDataInner{URL: "https://example.com/image.png"}.SHA256()
+	checks.ErrorIs(t, err, openai.ErrChecksumRequiresDownload, "url entries cannot be hashed offline")
+}
+
+func TestImageChecksumsDownloadsURLs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	}))
+	defer ts.Close()
+
+	client := openai.NewClient(test.GetTestToken())
+	checksums, err := client.ImageChecksums(context.Background(), openai.ImageResponse{
+		Data: []openai.ImageResponseDataInner{{URL: ts.URL + "/image.png"}},
+	})
+	checks.NoError(t, err, "ImageChecksums error")
+	if checksums[0] != "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9" {
+		t.Errorf("unexpected checksum %s", checksums[0])
+	}
+}
+
+// memoryFS is an in-memory openai.WritableFS.
+type memoryFS struct {
+	files map[string]*bytes.Buffer
+}
+
+type memoryFile struct {
+	*bytes.Buffer
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+func (m *memoryFS) Create(name string) (io.WriteCloser, error) {
+	buf := &bytes.Buffer{}
+	m.files[name] = buf
+	return memoryFile{buf}, nil
+}
+
+func TestSaveImagesFS(t *testing.T) {
+	client := openai.NewClient(test.GetTestToken())
+	png := encodeTestPNG(t, 2, 2)
+	response := openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+		{B64JSON: base64.StdEncoding.EncodeToString(png)},
+	}}
+
+	fsys := &memoryFS{files: make(map[string]*bytes.Buffer)}
+	names, err := client.SaveImagesFS(context.Background(), response, fsys, openai.SaveImageOptions{})
+	checks.NoError(t, err, "SaveImagesFS error")
+	if len(names) != 1 || names[0] != "image-0.png" {
+		t.Fatalf("expected image-0.png to be written, got %v", names)
+	}
+	if !bytes.Equal(fsys.files["image-0.png"].Bytes(), png) {
+		t.Error("unexpected content written to the file system")
+	}
+}
+
+func TestDirFSRejectsInvalidNames(t *testing.T) {
+	_, err := openai.DirFS(t.TempDir()).Create("../escape.png")
+	checks.ErrorIs(t, err, fs.ErrInvalid, "names outside the directory should be rejected")
+}
+
+func TestDecodeImage(t *testing.T) {
+	entry := openai.ImageResponseDataInner{B64JSON: base64.StdEncoding.EncodeToString(encodeTestPNG(t, 3, 2))}
+	img, format, err := entry.DecodeImage()
+	checks.NoError(t, err, "DecodeImage error")
+	if format != "png" || img.Bounds().Dx() != 3 || img.Bounds().Dy() != 2 {
+		t.Errorf("expected a 3x2 png, got a %dx%d %s", img.Bounds().Dx(), img.Bounds().Dy(), format)
+	}
+
+	_, _, err = openai.ImageResponseDataInner{URL: "https://example.com/image.png"}.DecodeImage()
+	checks.ErrorIs(t, err, openai.ErrNoImageData, "expected url entries to be rejected")
+	_, _, err = openai.ImageResponseDataInner{}.DecodeImage()
+	checks.ErrorIs(t, err, openai.ErrNoImageData, "expected empty entries to be rejected")
+}
+
+func TestDownloadImage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/expired.png" {
+			http.Error(w, "link expired", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte("url " + r.URL.Path))
+	}))
+	defer ts.Close()
+	client := openai.NewClient(test.GetTestToken())
+
+	data, err := client.DownloadImage(context.Background(), openai.ImageResponseDataInner{URL: ts.URL + "/0.png"})
+	checks.NoError(t, err, "DownloadImage error")
+	if string(data) != "url /0.png" {
+		t.Errorf("unexpected image %q", data)
+	}
+
+	_, err = client.DownloadImage(context.Background(), openai.ImageResponseDataInner{URL: ts.URL + "/expired.png"})
+	checks.ErrorIs(t, err, openai.ErrImageDownloadFailed, "expected a failed download")
+	if err != nil && !strings.Contains(err.Error(), "403") {
+		t.Errorf("expected the error to include the status code, got %v", err)
+	}
+
+	_, err = client.DownloadImage(context.Background(), openai.ImageResponseDataInner{B64JSON: "aW1hZ2U="})
+	checks.ErrorIs(t, err, openai.ErrNoImageData, "expected entries without a url to be rejected")
+
+	images, err := client.DownloadImages(context.Background(), openai.ImageResponse{Data: []openai.ImageResponseDataInner{
+		{URL: ts.URL + "/0.png"},
+		{URL: ts.URL + "/1.png"},
+	}})
+	checks.NoError(t, err, "DownloadImages error")
+	if len(images) != 2 || string(images[1]) != "url /1.png" {
+		t.Errorf("unexpected images %q", images)
+	}
+}
@@ -0,0 +1,280 @@
+package openai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Image stream event types.
+const (
+	ImageStreamEventPartialImage = "image_generation.partial_image"
+	ImageStreamEventCompleted    = "image_generation.completed"
+	// ImageStreamEventError is sent instead of the remaining events when the generation fails.
+	ImageStreamEventError = "error"
+)
+
+// ImageStreamEvent is a server-sent event of a streamed image generation.
+type ImageStreamEvent struct {
+	Type              string              `json:"type"`
+	B64JSON           string              `json:"b64_json,omitempty"`
+	PartialImageIndex int                 `json:"partial_image_index,omitempty"`
+	CreatedAt         int64               `json:"created_at,omitempty"`
+	Size              string              `json:"size,omitempty"`
+	Quality           string              `json:"quality,omitempty"`
+	Background        string              `json:"background,omitempty"`
+	OutputFormat      string              `json:"output_format,omitempty"`
+	Usage             *ImageResponseUsage `json:"usage,omitempty"`
+
+	// ImageIndex identifies the image an event belongs to when more than one image is requested,
+	// as partials of different images may interleave.
+	ImageIndex int `json:"image_index,omitempty"`
+}
+
+// ImageStream receives the events of a streamed image generation.
+type ImageStream struct {
+	*streamReader[ImageStreamEvent]
+}
+
+// Recv returns the next event of the stream, or io.EOF once the stream has ended. Error events
+// sent by the server are returned as an *APIError. Once the context of the stream is done,
+// Recv returns the context error.
+func (stream *ImageStream) Recv() (event ImageStreamEvent, err error) {
+	rawLine, err := stream.RecvRaw()
+	if err != nil {
+		return event, stream.contextError(err)
+	}
+	if err = stream.unmarshaler.Unmarshal(rawLine, &event); err != nil {
+		return event, err
+	}
+	if event.Type == ImageStreamEventError {
+		stream.isFinished = true
+		return event, imageStreamError(rawLine)
+	}
+	return event, nil
+}
+
+// contextError replaces an error caused by the cancellation of the stream with the context error.
+func (stream *ImageStream) contextError(err error) error {
+	if errors.Is(err, io.EOF) || stream.response == nil || stream.response.Request == nil {
+		return err
+	}
+	if ctxErr := stream.response.Request.Context().Err(); ctxErr != nil {
+		stream.isFinished = true
+		return ctxErr
+	}
+	return err
+}
+
+// imageStreamError decodes an error event, which carries the error either nested in an "error"
+// member or at its top level.
+func imageStreamError(data []byte) error {
+	var payload struct {
+		Error *APIError `json:"error"`
+	}
+	if err := json.Unmarshal(data, &payload); err == nil && payload.Error != nil {
+		return fmt.Errorf("error, %w", payload.Error)
+	}
+	apiErr := &APIError{}
+	if err := json.Unmarshal(data, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = "image stream error event"
+	}
+	return fmt.Errorf("error, %w", apiErr)
+}
+
+// CreateImageStream — API call to create an image w/ streaming support (gpt-image-1 only).
+// The server sends PartialImages partial_image events while the image is rendered,
+// followed by a completed event carrying the final image. Close the stream when done;
+// canceling ctx ends it as well. Requests for more than one image are rejected with
+// ErrStreamMultipleImages unless ClientConfig.AllowMultiImageStreams is set.
+func (c *Client) CreateImageStream(ctx context.Context, request ImageRequest) (stream *ImageStream, err error) {
+	err = c.validateImageRequest(request)
+	if err == nil && request.N > 1 && !c.config.AllowMultiImageStreams {
+		err = fmt.Errorf("%w: n=%d, partials of different images interleave in the stream "+
+			"and some servers do not support it, set ClientConfig.AllowMultiImageStreams to stream them",
+			ErrStreamMultipleImages, request.N)
+	}
+	if err != nil {
+		err = newValidationError(err)
+		return
+	}
+
+	request.Size = normalizeRequestSize(request.Size)
+	request.Stream = true
+	req, err := c.newRequest(
+		ctx,
+		http.MethodPost,
+		c.fullURL("/images/generations", withModel(request.Model)),
+		withBody(request),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := sendRequestStream[ImageStreamEvent](c, req)
+	if err != nil {
+		return
+	}
+	stream = &ImageStream{
+		streamReader: resp,
+	}
+	return
+}
+
+// CreateImageStreamToDir streams an image generation and writes every partial image and the
+// final image to numbered files in dir, in the order they arrive. It returns the written paths.
+func (c *Client) CreateImageStreamToDir(ctx context.Context, request ImageRequest, dir string) ([]string, error) {
+	stream, err := c.CreateImageStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var paths []string
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			return paths, nil
+		}
+		if recvErr != nil {
+			return paths, recvErr
+		}
+
+		name := fmt.Sprintf("%03d", len(paths))
+		if request.N > 1 {
+			name += fmt.Sprintf("-image-%d", event.ImageIndex)
+		}
+		switch event.Type {
+		case ImageStreamEventPartialImage:
+			name += fmt.Sprintf("-partial-%d", event.PartialImageIndex)
+		case ImageStreamEventCompleted:
+			name += "-final"
+		default:
+			continue
+		}
+
+		path := filepath.Join(dir, name+"."+imageFileExtension(event.OutputFormat, request.OutputFormat))
+		if err = writeB64File(path, event.B64JSON); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+}
+
+// CreateImageFirstPartial streams an image generation and returns the base64 data of the first
+// image the server sends, usually a partial one, as soon as it arrives, so that a UI can show
+// it right away. The final images are delivered on the returned channel once the stream ends,
+// as a response with one image per requested image and the usage of the generation. If the
+// stream fails after the first image, the channel is closed without a value. PartialImages
+// defaults to 1, since the server sends no partials otherwise.
+func (c *Client) CreateImageFirstPartial(
+	ctx context.Context,
+	request ImageRequest,
+) (b64 string, finalCh <-chan ImageResponse, err error) {
+	if request.PartialImages == 0 {
+		request.PartialImages = 1
+	}
+	stream, err := c.CreateImageStream(ctx, request)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var final imageStreamCollector
+	for b64 == "" {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			stream.Close()
+			return "", nil, ErrNoImageData
+		}
+		if recvErr != nil {
+			stream.Close()
+			return "", nil, recvErr
+		}
+		if event.Type == ImageStreamEventPartialImage || event.Type == ImageStreamEventCompleted {
+			b64 = event.B64JSON
+		}
+		final.add(event)
+	}
+
+	ch := make(chan ImageResponse, 1)
+	go func() {
+		defer close(ch)
+		defer stream.Close()
+		for {
+			event, recvErr := stream.Recv()
+			if errors.Is(recvErr, io.EOF) {
+				break
+			}
+			if recvErr != nil {
+				return
+			}
+			final.add(event)
+		}
+		if response, ok := final.response(); ok {
+			ch <- response
+		}
+	}()
+	return b64, ch, nil
+}
+
+// imageStreamCollector assembles the completed events of an image stream into a response.
+type imageStreamCollector struct {
+	completed []*ImageStreamEvent
+	created   int64
+	usage     ImageResponseUsage
+}
+
+func (s *imageStreamCollector) add(event ImageStreamEvent) {
+	if event.Type != ImageStreamEventCompleted || event.ImageIndex < 0 {
+		return
+	}
+	for len(s.completed) <= event.ImageIndex {
+		s.completed = append(s.completed, nil)
+	}
+	s.completed[event.ImageIndex] = &event
+	if event.CreatedAt > s.created {
+		s.created = event.CreatedAt
+	}
+	if event.Usage != nil {
+		s.usage = s.usage.plus(*event.Usage)
+	}
+}
+
+// response returns the collected images, or false if an image did not complete.
+func (s *imageStreamCollector) response() (ImageResponse, bool) {
+	if len(s.completed) == 0 {
+		return ImageResponse{}, false
+	}
+	response := ImageResponse{Created: s.created, Usage: s.usage}
+	for _, event := range s.completed {
+		if event == nil {
+			return ImageResponse{}, false
+		}
+		response.Data = append(response.Data, ImageResponseDataInner{B64JSON: event.B64JSON})
+	}
+	return response, true
+}
+
+// imageFileExtension returns the file extension for the first non-empty output format, defaulting to png.
+func imageFileExtension(formats ...string) string {
+	for _, format := range formats {
+		if format != "" {
+			return format
+		}
+	}
+	return CreateImageOutputFormatPNG
+}
+
+func writeB64File(path, b64 string) error {
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,278 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// handleImageStreamEndpoint checks that streaming was requested and sends events as SSE.
+func handleImageStreamEndpoint(t *testing.T, events ...openai.ImageStreamEvent) func(http.ResponseWriter, *http.Request) {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		request, err := getImageBody(r)
+		checks.NoError(t, err, "could not read request")
+		if !request.Stream {
+			http.Error(w, "expected a streaming request", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+	}
+}
+
+func TestCreateImageStreamToDir(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	server.RegisterHandler("/v1/images/generations", handleImageStreamEndpoint(t,
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventPartialImage, B64JSON: b64("partial 0")},
+		openai.ImageStreamEvent{
+			Type:              openai.ImageStreamEventPartialImage,
+			B64JSON:           b64("partial 1"),
+			PartialImageIndex: 1,
+		},
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventCompleted, B64JSON: b64("final")},
+	))
+
+	dir := t.TempDir()
+	paths, err := client.CreateImageStreamToDir(context.Background(), openai.ImageRequest{
+		Prompt:        "Lorem ipsum",
+		Model:         openai.CreateImageModelGptImage1,
+		PartialImages: 2,
+	}, dir)
+	checks.NoError(t, err, "CreateImageStreamToDir error")
+
+	expected := map[string]string{
+		filepath.Join(dir, "000-partial-0.png"): "partial 0",
+		filepath.Join(dir, "001-partial-1.png"): "partial 1",
+		filepath.Join(dir, "002-final.png"):     "final",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %d files, got %v", len(expected), paths)
+	}
+	for _, path := range paths {
+		content, readErr := os.ReadFile(path)
+		checks.NoError(t, readErr, "could not read written file")
+		if !bytes.Equal(content, []byte(expected[path])) {
+			t.Errorf("%s: expected %q, got %q", path, expected[path], content)
+		}
+	}
+}
+
+func TestCreateImageStreamInvalidPartialImages(t *testing.T) {
+	client, _, teardown := setupOpenAITestServer()
+	defer teardown()
+	_, err := client.CreateImageStream(context.Background(), openai.ImageRequest{
+		Prompt:        "Lorem ipsum",
+		Model:         openai.CreateImageModelGptImage1,
+		PartialImages: 4,
+	})
+	checks.ErrorIs(t, err, openai.ErrInvalidPartialImages, "CreateImageStream should validate partial images")
+}
+
+func TestImageStreamInterleavedImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.AllowMultiImageStreams = true
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleImageStreamEndpoint(t,
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventPartialImage, B64JSON: "a0", ImageIndex: 0},
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventPartialImage, B64JSON: "b0", ImageIndex: 1},
+		openai.ImageStreamEvent{
+			Type: openai.ImageStreamEventPartialImage, B64JSON: "b1", ImageIndex: 1, PartialImageIndex: 1,
+		},
+		openai.ImageStreamEvent{
+			Type: openai.ImageStreamEventPartialImage, B64JSON: "a1", ImageIndex: 0, PartialImageIndex: 1,
+		},
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventCompleted, B64JSON: "b", ImageIndex: 1},
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventCompleted, B64JSON: "a", ImageIndex: 0},
+	))
+
+	stream, err := client.CreateImageStream(context.Background(), openai.ImageRequest{
+		Prompt:        "Lorem ipsum",
+		Model:         openai.CreateImageModelGptImage1,
+		N:             2,
+		PartialImages: 2,
+	})
+	checks.NoError(t, err, "CreateImageStream error")
+	defer stream.Close()
+
+	groups := map[int][]string{}
+	for {
+		event, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoErrorF(t, recvErr, "Recv error")
+		groups[event.ImageIndex] = append(groups[event.ImageIndex], event.B64JSON)
+	}
+
+	if got := strings.Join(groups[0], ","); got != "a0,a1,a" {
+		t.Errorf("unexpected events for image 0: %s", got)
+	}
+	if got := strings.Join(groups[1], ","); got != "b0,b1,b" {
+		t.Errorf("unexpected events for image 1: %s", got)
+	}
+}
+
+func TestCreateImageFirstPartial(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	b64 := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+	server.RegisterHandler("/v1/images/generations", handleImageStreamEndpoint(t,
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventPartialImage, B64JSON: b64("partial 0")},
+		openai.ImageStreamEvent{
+			Type:      openai.ImageStreamEventCompleted,
+			B64JSON:   b64("final"),
+			CreatedAt: 1700000000,
+			Usage:     &openai.ImageResponseUsage{TotalTokens: 300, OutputTokens: 272},
+		},
+	))
+
+	partial, finalCh, err := client.CreateImageFirstPartial(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  openai.CreateImageModelGptImage1,
+	})
+	checks.NoError(t, err, "CreateImageFirstPartial error")
+	if partial != b64("partial 0") {
+		t.Errorf("expected the first partial, got %q", partial)
+	}
+
+	final, ok := <-finalCh
+	if !ok {
+		t.Fatal("expected a final response on the channel")
+	}
+	if len(final.Data) != 1 || final.Data[0].B64JSON != b64("final") {
+		t.Errorf("expected the final image, got %+v", final.Data)
+	}
+	if final.Created != 1700000000 || final.Usage.TotalTokens != 300 {
+		t.Errorf("unexpected final metadata: created %d, usage %+v", final.Created, final.Usage)
+	}
+	if _, ok = <-finalCh; ok {
+		t.Error("expected the channel to be closed after the final response")
+	}
+}
+
+// handleRawImageStreamEndpoint writes body as the event stream of an image generation.
+func handleRawImageStreamEndpoint(body string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, body)
+	}
+}
+
+func TestImageStreamErrorEvents(t *testing.T) {
+	cases := map[string]string{
+		"error event": "event: image_generation.partial_image\n" +
+			`data: {"type":"image_generation.partial_image","b64_json":"cA=="}` + "\n\n" +
+			"event: error\n" +
+			`data: {"type":"error","error":{"message":"generation failed","code":"server_error"}}` + "\n\n",
+		"error data line": "event: image_generation.partial_image\n" +
+			`data: {"type":"image_generation.partial_image","b64_json":"cA=="}` + "\n\n" +
+			"event: error\n" +
+			`data: {"error":{"message":"generation failed","code":"server_error"}}` + "\n\n",
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			server.RegisterHandler("/v1/images/generations", handleRawImageStreamEndpoint(body))
+
+			stream, err := client.CreateImageStream(context.Background(), openai.ImageRequest{
+				Prompt:        "Lorem ipsum",
+				Model:         openai.CreateImageModelGptImage1,
+				PartialImages: 1,
+			})
+			checks.NoError(t, err, "CreateImageStream error")
+			defer stream.Close()
+
+			event, err := stream.Recv()
+			checks.NoError(t, err, "Recv error")
+			if event.B64JSON != "cA==" {
+				t.Errorf("expected the partial image, got %+v", event)
+			}
+
+			_, err = stream.Recv()
+			var apiErr *openai.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("expected an *APIError, got %v", err)
+			}
+			if apiErr.Message != "generation failed" || apiErr.Code != "server_error" {
+				t.Errorf("unexpected error %+v", apiErr)
+			}
+		})
+	}
+}
+
+func TestImageStreamContextCancellation(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: image_generation.partial_image\n"+
+			`data: {"type":"image_generation.partial_image","b64_json":"cA=="}`+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.CreateImageStream(ctx, openai.ImageRequest{
+		Prompt:        "Lorem ipsum",
+		Model:         openai.CreateImageModelGptImage1,
+		PartialImages: 1,
+	})
+	checks.NoError(t, err, "CreateImageStream error")
+	defer stream.Close()
+
+	_, err = stream.Recv()
+	checks.NoError(t, err, "Recv error")
+
+	cancel()
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, context.Canceled, "expected Recv to return the context error")
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "expected the stream to stay finished")
+}
+
+func TestCreateImageStreamMultipleImages(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleImageStreamEndpoint(t,
+		openai.ImageStreamEvent{Type: openai.ImageStreamEventCompleted, B64JSON: "a"},
+	))
+	request := openai.ImageRequest{
+		Prompt:        "Lorem ipsum",
+		Model:         openai.CreateImageModelGptImage1,
+		N:             2,
+		PartialImages: 1,
+	}
+
+	_, err := client.CreateImageStream(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrStreamMultipleImages, "expected n=2 streams to be rejected")
+	if err != nil && !strings.Contains(err.Error(), "AllowMultiImageStreams") {
+		t.Errorf("expected the error to name the compatibility flag, got %v", err)
+	}
+
+	request.N = 1
+	stream, err := client.CreateImageStream(context.Background(), request)
+	checks.NoError(t, err, "single image streams should be allowed")
+	stream.Close()
+}
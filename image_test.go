@@ -43,6 +43,17 @@ func (fb *mockFormBuilder) FormDataContentType() string {
 	return ""
 }
 
+// endlessReader is a non-seekable image that never runs out, so that it can be passed to
+// several calls whose form builder does not read it.
+type endlessReader struct{}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
 func TestImageFormBuilderFailures(t *testing.T) {
 	config := DefaultConfig("")
 	config.BaseURL = ""
@@ -55,7 +66,8 @@ func TestImageFormBuilderFailures(t *testing.T) {
 	ctx := context.Background()
 
 	req := ImageEditRequest{
-		Mask: &os.File{},
+		Image: endlessReader{},
+		Mask:  &os.File{},
 	}
 
 	mockFailedErr := fmt.Errorf("mock form builder fail")
@@ -121,7 +133,7 @@ func TestVariImageFormBuilderFailures(t *testing.T) {
 	}
 	ctx := context.Background()
 
-	req := ImageVariRequest{}
+	req := ImageVariRequest{Image: endlessReader{}}
 
 	mockFailedErr := fmt.Errorf("mock form builder fail")
 	mockBuilder.mockCreateFormFileReader = func(string, io.Reader, string) error {
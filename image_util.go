@@ -0,0 +1,384 @@
+package openai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidImageSize       = errors.New("invalid image size, expected WIDTHxHEIGHT")
+	ErrUnsupportedImageSize   = errors.New("unsupported image size")
+	ErrUnsupportedImageFormat = errors.New("unsupported image format")
+	ErrInvalidImageDimensions = errors.New("image dimensions must be positive")
+	ErrTargetSizeUnreachable  = errors.New("image cannot be compressed to the target size")
+)
+
+// FitToSize scales img to fit within the dimensions of size while preserving its aspect ratio,
+// centering it on a transparent canvas of exactly that size. The size must be one of the sizes
+// supported by at least one image model. Note that dall-e-2 treats transparent pixels of the
+// image as the area to edit when no mask is provided, so the letterbox padding is editable.
+func FitToSize(img image.Image, size string) (image.Image, error) {
+	if !isKnownImageSize(size) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageSize, size)
+	}
+	width, height, err := parseImageSize(size)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	if srcWidth == 0 || srcHeight == 0 {
+		return nil, fmt.Errorf("cannot fit an empty image")
+	}
+
+	scaledWidth, scaledHeight := width, height
+	if srcWidth*height > srcHeight*width {
+		scaledHeight = srcHeight * width / srcWidth
+	} else {
+		scaledWidth = srcWidth * height / srcHeight
+	}
+	if scaledWidth == 0 {
+		scaledWidth = 1
+	}
+	if scaledHeight == 0 {
+		scaledHeight = 1
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, width, height))
+	scaled := resizeImage(img, scaledWidth, scaledHeight)
+	offsetX, offsetY := (width-scaledWidth)/2, (height-scaledHeight)/2
+	for y := 0; y < scaledHeight; y++ {
+		for x := 0; x < scaledWidth; x++ {
+			canvas.Set(offsetX+x, offsetY+y, scaled.At(x, y))
+		}
+	}
+	return canvas, nil
+}
+
+// contactSheetPadding is the gap in pixels between and around the cells of a contact sheet.
+const contactSheetPadding = 8
+
+// ContactSheet arranges images into a grid with cols columns on a white background.
+// Every cell is as large as the largest image, with images anchored to the cell's top-left corner.
+func ContactSheet(images []image.Image, cols int) (image.Image, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("contact sheet requires at least one image")
+	}
+	if cols < 1 {
+		return nil, fmt.Errorf("contact sheet requires at least one column, got %d", cols)
+	}
+	if cols > len(images) {
+		cols = len(images)
+	}
+	rows := (len(images) + cols - 1) / cols
+
+	var cellWidth, cellHeight int
+	for _, img := range images {
+		bounds := img.Bounds()
+		if bounds.Dx() > cellWidth {
+			cellWidth = bounds.Dx()
+		}
+		if bounds.Dy() > cellHeight {
+			cellHeight = bounds.Dy()
+		}
+	}
+
+	sheet := image.NewRGBA(image.Rect(
+		0, 0,
+		cols*cellWidth+(cols+1)*contactSheetPadding,
+		rows*cellHeight+(rows+1)*contactSheetPadding,
+	))
+	draw.Draw(sheet, sheet.Bounds(), image.White, image.Point{}, draw.Src)
+	for i, img := range images {
+		col, row := i%cols, i/cols
+		origin := image.Pt(
+			contactSheetPadding+col*(cellWidth+contactSheetPadding),
+			contactSheetPadding+row*(cellHeight+contactSheetPadding),
+		)
+		target := image.Rectangle{Min: origin, Max: origin.Add(img.Bounds().Size())}
+		draw.Draw(sheet, target, img, img.Bounds().Min, draw.Over)
+	}
+	return sheet, nil
+}
+
+// gifDelayUnit is the unit of GIF frame delays.
+const gifDelayUnit = 10 * time.Millisecond
+
+// AnimatePreview encodes images, such as the variations of an image, as the frames of a looping
+// animated GIF, showing each frame for delayMs milliseconds. GIF delays have a resolution of
+// 10ms. Frames are dithered to the web-safe palette, so the preview is not color accurate, and
+// frames smaller than the largest one are anchored to the top-left corner.
+func AnimatePreview(images []image.Image, delayMs int) ([]byte, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("animated preview requires at least one image")
+	}
+	delay := int(time.Duration(delayMs) * time.Millisecond / gifDelayUnit)
+
+	animation := &gif.GIF{}
+	for _, img := range images {
+		bounds := img.Bounds()
+		frame := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), palette.WebSafe)
+		draw.FloydSteinberg.Draw(frame, frame.Bounds(), img, bounds.Min)
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, delay)
+		if bounds.Dx() > animation.Config.Width {
+			animation.Config.Width = bounds.Dx()
+		}
+		if bounds.Dy() > animation.Config.Height {
+			animation.Config.Height = bounds.Dy()
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, animation); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// HasTransparency reports whether img has any pixel that is not fully opaque, for example to
+// check that a generation requested with a transparent background actually has one.
+func HasTransparency(img image.Image) bool {
+	// The standard image types can answer directly and usually faster than a pixel scan.
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return !o.Opaque()
+	}
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a != 0xffff {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ConvertImage decodes a png or jpeg image and re-encodes it as targetFormat, one of
+// CreateImageOutputFormatPNG, CreateImageOutputFormatJPEG and CreateImageOutputFormatWEBP.
+// quality is the jpeg quality from 1 to 100, with 0 selecting the encoder default; it is
+// ignored for png and webp. webp output is lossless and needs building with the webp tag,
+// otherwise it fails with ErrUnsupportedImageFormat. Decoding webp input requires importing
+// golang.org/x/image/webp.
+func ConvertImage(data []byte, targetFormat string, quality int) ([]byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	switch targetFormat {
+	case CreateImageOutputFormatPNG:
+		err = png.Encode(&buf, img)
+	case CreateImageOutputFormatJPEG, "jpg":
+		if quality == 0 {
+			quality = jpeg.DefaultQuality
+		}
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	case CreateImageOutputFormatWEBP:
+		err = encodeWebP(&buf, img)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedImageFormat, targetFormat)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// minCompressQuality and maxCompressQuality bound the jpeg qualities CompressToTarget tries.
+const (
+	minCompressQuality = 10
+	maxCompressQuality = 100
+)
+
+// CompressToTarget encodes img as a jpeg of at most targetBytes, picking the highest quality
+// that fits with a binary search between 10 and 100. It returns the encoded image and the
+// jpeg quality used, where higher means better quality and less compression. The standard
+// library has no webp encoder, so only jpeg is produced. If the image does not fit even at
+// the lowest quality, that encoding is returned with an error wrapping ErrTargetSizeUnreachable.
+func CompressToTarget(img image.Image, targetBytes int) (data []byte, compression int, err error) {
+	low, high := minCompressQuality, maxCompressQuality
+	for low <= high {
+		quality := (low + high) / 2
+		var buf bytes.Buffer
+		if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, 0, err
+		}
+		if buf.Len() > targetBytes {
+			high = quality - 1
+			continue
+		}
+		data, compression = buf.Bytes(), quality
+		low = quality + 1
+	}
+	if data != nil {
+		return data, compression, nil
+	}
+
+	var buf bytes.Buffer
+	if err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: minCompressQuality}); err != nil {
+		return nil, 0, err
+	}
+	return buf.Bytes(), minCompressQuality, fmt.Errorf("%w: %d bytes at quality %d, target %d",
+		ErrTargetSizeUnreachable, buf.Len(), minCompressQuality, targetBytes)
+}
+
+// ResizeMaskTo decodes a mask image and scales it to width x height, the dimensions of the
+// image it applies to, as required by the edit endpoints. Transparency, which marks the areas
+// to edit, is kept. The result is png encoded.
+func ResizeMaskTo(mask io.Reader, width, height int) (io.Reader, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("%w, got %dx%d", ErrInvalidImageDimensions, width, height)
+	}
+	img, _, err := image.Decode(mask)
+	if err != nil {
+		return nil, err
+	}
+
+	var resized image.Image = img
+	if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+		resized = resizeImage(img, width, height)
+	}
+	var buf bytes.Buffer
+	err = png.Encode(&buf, resized)
+	if err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// parseImageSize parses a size string such as "1024x1536" into its width and height.
+func parseImageSize(size string) (width, height int, err error) {
+	w, h, ok := strings.Cut(size, "x")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidImageSize, size)
+	}
+	width, err = strconv.Atoi(w)
+	if err != nil || width <= 0 {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidImageSize, size)
+	}
+	height, err = strconv.Atoi(h)
+	if err != nil || height <= 0 {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidImageSize, size)
+	}
+	return width, height, nil
+}
+
+// NormalizeSize canonicalizes a user-supplied size such as "1024 X 1024" to the "1024x1024"
+// form used by the API, and checks that the result is supported by at least one image model.
+func NormalizeSize(s string) (string, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	normalized = strings.ReplaceAll(normalized, "×", "x")
+	if normalized == CreateImageSizeAuto {
+		return normalized, nil
+	}
+
+	width, height, err := parseImageSize(normalized)
+	if err != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidImageSize, s)
+	}
+	normalized = fmt.Sprintf("%dx%d", width, height)
+	if !isKnownImageSize(normalized) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedImageSize, normalized)
+	}
+	return normalized, nil
+}
+
+// normalizeRequestSize returns the canonical form of size, or size itself when it cannot be normalized.
+func normalizeRequestSize(size string) string {
+	if normalized, err := NormalizeSize(size); err == nil {
+		return normalized
+	}
+	return size
+}
+
+// SizeAspectRatio returns the width to height ratio of a size string, e.g. 1.75 for "1792x1024".
+func SizeAspectRatio(size string) (float64, error) {
+	width, height, err := parseImageSize(size)
+	if err != nil {
+		return 0, err
+	}
+	return float64(width) / float64(height), nil
+}
+
+// isKnownImageSize reports whether size is supported by at least one image model.
+func isKnownImageSize(size string) bool {
+	for _, info := range imageModels {
+		if info.SupportsSize(size) {
+			return true
+		}
+	}
+	return false
+}
+
+// resizeImage scales src to width x height using bilinear interpolation.
+func resizeImage(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+
+	for y := 0; y < height; y++ {
+		sy := (float64(y)+0.5)*float64(srcHeight)/float64(height) - 0.5
+		y0, fy := splitCoordinate(sy, srcHeight)
+		y1 := nextCoordinate(y0, srcHeight)
+		for x := 0; x < width; x++ {
+			sx := (float64(x)+0.5)*float64(srcWidth)/float64(width) - 0.5
+			x0, fx := splitCoordinate(sx, srcWidth)
+			x1 := nextCoordinate(x0, srcWidth)
+
+			c00 := color.RGBA64Model.Convert(src.At(bounds.Min.X+x0, bounds.Min.Y+y0)).(color.RGBA64)
+			c10 := color.RGBA64Model.Convert(src.At(bounds.Min.X+x1, bounds.Min.Y+y0)).(color.RGBA64)
+			c01 := color.RGBA64Model.Convert(src.At(bounds.Min.X+x0, bounds.Min.Y+y1)).(color.RGBA64)
+			c11 := color.RGBA64Model.Convert(src.At(bounds.Min.X+x1, bounds.Min.Y+y1)).(color.RGBA64)
+
+			dst.Set(x, y, color.RGBA64{
+				R: lerpChannel(c00.R, c10.R, c01.R, c11.R, fx, fy),
+				G: lerpChannel(c00.G, c10.G, c01.G, c11.G, fx, fy),
+				B: lerpChannel(c00.B, c10.B, c01.B, c11.B, fx, fy),
+				A: lerpChannel(c00.A, c10.A, c01.A, c11.A, fx, fy),
+			})
+		}
+	}
+	return dst
+}
+
+// splitCoordinate clamps a source coordinate and splits it into its integer and fractional parts.
+func splitCoordinate(v float64, limit int) (int, float64) {
+	if v < 0 {
+		return 0, 0
+	}
+	i := int(v)
+	if i >= limit-1 {
+		return limit - 1, 0
+	}
+	return i, v - float64(i)
+}
+
+// nextCoordinate returns the neighbouring source coordinate, clamped to the image.
+func nextCoordinate(i, limit int) int {
+	if i+1 >= limit {
+		return limit - 1
+	}
+	return i + 1
+}
+
+func lerpChannel(c00, c10, c01, c11 uint16, fx, fy float64) uint16 {
+	top := float64(c00)*(1-fx) + float64(c10)*fx
+	bottom := float64(c01)*(1-fx) + float64(c11)*fx
+	return uint16(top*(1-fy) + bottom*fy)
+}
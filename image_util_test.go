@@ -0,0 +1,280 @@
+package openai_test
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func newFilledImage(width, height int, c color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func TestFitToSize(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	src := newFilledImage(300, 150, red)
+
+	fitted, err := openai.FitToSize(src, openai.CreateImageSize1024x1024)
+	checks.NoError(t, err, "FitToSize error")
+
+	bounds := fitted.Bounds()
+	if bounds.Dx() != 1024 || bounds.Dy() != 1024 {
+		t.Fatalf("expected 1024x1024, got %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	// The 2:1 input is scaled to 1024x512 and centered vertically.
+	if _, _, _, a := fitted.At(512, 100).RGBA(); a != 0 {
+		t.Errorf("expected letterbox padding to be transparent, got alpha %d", a)
+	}
+	if r, _, _, a := fitted.At(512, 512).RGBA(); r != 0xffff || a != 0xffff {
+		t.Errorf("expected image content at the center, got r=%d a=%d", r, a)
+	}
+}
+
+func TestFitToSizeUnsupportedSize(t *testing.T) {
+	src := newFilledImage(10, 10, color.White)
+	_, err := openai.FitToSize(src, "1000x1000")
+	if !errors.Is(err, openai.ErrUnsupportedImageSize) {
+		t.Fatalf("expected ErrUnsupportedImageSize, got %v", err)
+	}
+}
+
+func TestSizeAspectRatio(t *testing.T) {
+	cases := map[string]float64{
+		openai.CreateImageSize256x256:   1,
+		openai.CreateImageSize512x512:   1,
+		openai.CreateImageSize1024x1024: 1,
+		openai.CreateImageSize1792x1024: 1.75,
+		openai.CreateImageSize1024x1792: 1024.0 / 1792.0,
+		openai.CreateImageSize1536x1024: 1.5,
+		openai.CreateImageSize1024x1536: 1024.0 / 1536.0,
+	}
+	for size, expected := range cases {
+		ratio, err := openai.SizeAspectRatio(size)
+		checks.NoError(t, err, "SizeAspectRatio error")
+		if ratio != expected {
+			t.Errorf("%s: expected %v, got %v", size, expected, ratio)
+		}
+	}
+}
+
+func TestSizeAspectRatioMalformed(t *testing.T) {
+	for _, size := range []string{"", "1024", "1024x", "x1024", "0x1024", "-1x5", "axb", "1024x1024x1"} {
+		if _, err := openai.SizeAspectRatio(size); !errors.Is(err, openai.ErrInvalidImageSize) {
+			t.Errorf("%q: expected ErrInvalidImageSize, got %v", size, err)
+		}
+	}
+}
+
+func TestContactSheet(t *testing.T) {
+	images := []image.Image{
+		newFilledImage(20, 10, color.RGBA{R: 255, A: 255}),
+		newFilledImage(20, 10, color.RGBA{G: 255, A: 255}),
+		newFilledImage(20, 10, color.RGBA{B: 255, A: 255}),
+		newFilledImage(10, 20, color.Black),
+	}
+	sheet, err := openai.ContactSheet(images, 2)
+	checks.NoError(t, err, "ContactSheet error")
+
+	// Two columns and two rows of 20x20 cells, with 8px padding around and between them.
+	bounds := sheet.Bounds()
+	if bounds.Dx() != 2*20+3*8 || bounds.Dy() != 2*20+3*8 {
+		t.Fatalf("unexpected contact sheet bounds %v", bounds)
+	}
+	if r, g, _, _ := sheet.At(8+20+8, 8).RGBA(); r != 0 || g != 0xffff {
+		t.Errorf("expected the second image at the top-right cell, got r=%d g=%d", r, g)
+	}
+	if r, g, b, _ := sheet.At(0, 0).RGBA(); r != 0xffff || g != 0xffff || b != 0xffff {
+		t.Error("expected white padding")
+	}
+}
+
+func TestContactSheetInvalidInput(t *testing.T) {
+	_, err := openai.ContactSheet(nil, 2)
+	checks.HasError(t, err, "ContactSheet should fail without images")
+
+	_, err = openai.ContactSheet([]image.Image{newFilledImage(1, 1, color.White)}, 0)
+	checks.HasError(t, err, "ContactSheet should fail without columns")
+}
+
+func TestNormalizeSize(t *testing.T) {
+	cases := map[string]string{
+		"1024x1024":     openai.CreateImageSize1024x1024,
+		"1024X1024":     openai.CreateImageSize1024x1024,
+		" 1792 x 1024 ": openai.CreateImageSize1792x1024,
+		"1024\tX\t1536": openai.CreateImageSize1024x1536,
+		"1536×1024":     openai.CreateImageSize1536x1024,
+		"0256x0256":     openai.CreateImageSize256x256,
+		" AUTO ":        openai.CreateImageSizeAuto,
+		"512 \n x 512 ": openai.CreateImageSize512x512,
+	}
+	for input, expected := range cases {
+		size, err := openai.NormalizeSize(input)
+		checks.NoError(t, err, "NormalizeSize error")
+		if size != expected {
+			t.Errorf("%q: expected %q, got %q", input, expected, size)
+		}
+	}
+
+	if _, err := openai.NormalizeSize("1024 by 1024"); !errors.Is(err, openai.ErrInvalidImageSize) {
+		t.Errorf("expected ErrInvalidImageSize, got %v", err)
+	}
+	if _, err := openai.NormalizeSize("1000 x 1000"); !errors.Is(err, openai.ErrUnsupportedImageSize) {
+		t.Errorf("expected ErrUnsupportedImageSize, got %v", err)
+	}
+}
+
+// pixelOnlyImage hides the Opaque method of the wrapped image to exercise the pixel scan.
+type pixelOnlyImage struct {
+	img image.Image
+}
+
+func (p pixelOnlyImage) ColorModel() color.Model { return p.img.ColorModel() }
+func (p pixelOnlyImage) Bounds() image.Rectangle { return p.img.Bounds() }
+func (p pixelOnlyImage) At(x, y int) color.Color { return p.img.At(x, y) }
+
+func TestHasTransparency(t *testing.T) {
+	opaque := newFilledImage(4, 4, color.White)
+	transparent := newFilledImage(4, 4, color.White)
+	transparent.Set(2, 3, color.NRGBA{R: 255, A: 128})
+
+	for _, wrap := range []func(image.Image) image.Image{
+		func(img image.Image) image.Image { return img },
+		func(img image.Image) image.Image { return pixelOnlyImage{img} },
+	} {
+		if openai.HasTransparency(wrap(opaque)) {
+			t.Error("expected an opaque image to have no transparency")
+		}
+		if !openai.HasTransparency(wrap(transparent)) {
+			t.Error("expected a partially transparent image to have transparency")
+		}
+	}
+}
+
+func TestConvertImage(t *testing.T) {
+	original := encodeTestPNG(t, 8, 4)
+
+	converted, err := openai.ConvertImage(original, openai.CreateImageOutputFormatJPEG, 90)
+	checks.NoError(t, err, "ConvertImage to jpeg error")
+	if contentType := http.DetectContentType(converted); contentType != "image/jpeg" {
+		t.Fatalf("expected jpeg output, got %s", contentType)
+	}
+
+	roundTrip, err := openai.ConvertImage(converted, openai.CreateImageOutputFormatPNG, 0)
+	checks.NoError(t, err, "ConvertImage to png error")
+	config, err := png.DecodeConfig(bytes.NewReader(roundTrip))
+	checks.NoError(t, err, "expected png output")
+	if config.Width != 8 || config.Height != 4 {
+		t.Errorf("expected dimensions to be preserved, got %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestResizeMaskTo(t *testing.T) {
+	mask := newFilledImage(4, 4, color.White)
+	// The top-left quadrant is the fully transparent area to edit.
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 2; x++ {
+			mask.Set(x, y, color.Transparent)
+		}
+	}
+	var buf bytes.Buffer
+	checks.NoError(t, png.Encode(&buf, mask), "png.Encode error")
+
+	resized, err := openai.ResizeMaskTo(&buf, 16, 8)
+	checks.NoError(t, err, "ResizeMaskTo error")
+	img, err := png.Decode(resized)
+	checks.NoError(t, err, "expected a png mask")
+
+	if bounds := img.Bounds(); bounds.Dx() != 16 || bounds.Dy() != 8 {
+		t.Fatalf("expected a 16x8 mask, got %v", bounds)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("expected the edit area to stay transparent, got alpha %d", a)
+	}
+	if _, _, _, a := img.At(15, 7).RGBA(); a != 0xffff {
+		t.Errorf("expected the kept area to stay opaque, got alpha %d", a)
+	}
+
+	_, err = openai.ResizeMaskTo(&buf, 0, 8)
+	checks.ErrorIs(t, err, openai.ErrInvalidImageDimensions, "non-positive dimensions should be rejected")
+}
+
+func TestAnimatePreview(t *testing.T) {
+	frames := []image.Image{
+		newFilledImage(4, 4, color.RGBA{R: 255, A: 255}),
+		newFilledImage(4, 4, color.RGBA{B: 255, A: 255}),
+	}
+	data, err := openai.AnimatePreview(frames, 500)
+	checks.NoError(t, err, "AnimatePreview error")
+
+	animation, err := gif.DecodeAll(bytes.NewReader(data))
+	checks.NoError(t, err, "gif.DecodeAll error")
+	if len(animation.Image) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(animation.Image))
+	}
+	if animation.Delay[0] != 50 {
+		t.Errorf("expected a 500ms delay of 50 hundredths, got %d", animation.Delay[0])
+	}
+	if r, _, b, _ := animation.Image[1].At(0, 0).RGBA(); b>>8 != 255 || r != 0 {
+		t.Errorf("expected the second frame to be blue, got %v", animation.Image[1].At(0, 0))
+	}
+
+	_, err = openai.AnimatePreview(nil, 500)
+	checks.HasError(t, err, "AnimatePreview should fail without frames")
+}
+
+// newNoiseImage returns an image of pseudo-random pixels, which compresses poorly.
+func newNoiseImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	seed := uint32(1)
+	for i := range img.Pix {
+		seed = seed*1664525 + 1013904223
+		img.Pix[i] = byte(seed >> 24)
+	}
+	return img
+}
+
+func TestCompressToTarget(t *testing.T) {
+	img := newNoiseImage(64, 64)
+	var best bytes.Buffer
+	checks.NoError(t, jpeg.Encode(&best, img, &jpeg.Options{Quality: 100}), "jpeg.Encode error")
+
+	target := best.Len() / 2
+	data, quality, err := openai.CompressToTarget(img, target)
+	checks.NoError(t, err, "CompressToTarget error")
+	if len(data) > target {
+		t.Errorf("expected at most %d bytes, got %d", target, len(data))
+	}
+	if quality < 10 || quality >= 100 {
+		t.Errorf("expected a reduced quality, got %d", quality)
+	}
+	if _, decodeErr := jpeg.Decode(bytes.NewReader(data)); decodeErr != nil {
+		t.Errorf("expected a jpeg, got %v", decodeErr)
+	}
+
+	data, quality, err = openai.CompressToTarget(img, best.Len())
+	checks.NoError(t, err, "CompressToTarget error")
+	if quality != 100 || len(data) != best.Len() {
+		t.Errorf("expected full quality when it fits, got quality %d and %d bytes", quality, len(data))
+	}
+
+	_, quality, err = openai.CompressToTarget(img, 10)
+	checks.ErrorIs(t, err, openai.ErrTargetSizeUnreachable, "tiny targets should be unreachable")
+	if quality != 10 {
+		t.Errorf("expected the minimum quality, got %d", quality)
+	}
+}
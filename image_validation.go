@@ -0,0 +1,371 @@
+package openai
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrImageNUnsupported            = errors.New("unsupported number of images for this model")
+	ErrInvalidOutputCompression     = errors.New("output compression must be between 0 and 100")
+	ErrOutputCompressionUnsupported = errors.New("output compression is not supported for png output")
+	ErrInvalidPartialImages         = errors.New("partial images must be between 0 and 3")
+	ErrUnsupportedImageQuality      = errors.New("unsupported image quality for this model")
+	ErrNoEditImages                 = errors.New("at least one image is required")
+	ErrNilEditImage                 = errors.New("edit image cannot be nil")
+	ErrNilVariationImage            = errors.New("variation image cannot be nil")
+	ErrMisalignedFileNames          = errors.New("file names must have one entry per image")
+	ErrEmptyImage                   = errors.New("image has no content")
+	ErrNotOpenAIField               = errors.New("field is not supported by the OpenAI API")
+	ErrUnsupportedOutputFormat      = errors.New("unsupported output format for this model")
+	ErrUnknownImageRequest          = errors.New("unknown image request type")
+	ErrTooManyB64Images             = errors.New("too many b64_json images requested")
+	ErrTransparentAutoSize          = errors.New("transparent backgrounds need an explicit size")
+	ErrInvalidPromptEncoding        = errors.New("prompt is not valid UTF-8")
+	ErrImageTooSmall                = errors.New("image is smaller than the minimum dimension")
+	ErrStreamMultipleImages         = errors.New("streaming more than one image is not enabled")
+)
+
+const (
+	// maxOutputCompression is the highest output_compression accepted by the API.
+	maxOutputCompression = 100
+	// maxPartialImages is the highest partial_images accepted by the API.
+	maxPartialImages = 3
+)
+
+// Validate checks the request against the known capabilities of its model.
+// Requests for unknown models are only checked for model-independent constraints.
+func (r ImageRequest) Validate() error {
+	if err := ValidatePromptEncoding(r.Prompt); err != nil {
+		return err
+	}
+	if err := validateImageN(r.Model, r.N); err != nil {
+		return err
+	}
+	if err := validateImageSize(r.Model, r.Size); err != nil {
+		return err
+	}
+	if err := validateImageQuality(r.Model, r.Quality); err != nil {
+		return err
+	}
+	if r.PartialImages < 0 || r.PartialImages > maxPartialImages {
+		return fmt.Errorf("%w, got %d", ErrInvalidPartialImages, r.PartialImages)
+	}
+	if err := validateOutputFormat(r.Model, r.OutputFormat); err != nil {
+		return err
+	}
+	return validateOutputCompression(r.Model, r.OutputFormat, r.OutputCompression)
+}
+
+// Validate checks that the edit has an image and that its parameters are supported by its model.
+func (r ImageEditRequest) Validate() error {
+	if err := ValidatePromptEncoding(r.Prompt); err != nil {
+		return err
+	}
+	if err := validateImageN(r.Model, r.N); err != nil {
+		return err
+	}
+	if err := validateImageSize(r.Model, r.Size); err != nil {
+		return err
+	}
+	if err := validateImageQuality(r.Model, r.Quality); err != nil {
+		return err
+	}
+	if err := validateOutputFormat(r.Model, r.OutputFormat); err != nil {
+		return err
+	}
+	if err := validateOutputCompression(r.Model, r.OutputFormat, r.OutputCompression); err != nil {
+		return err
+	}
+	if r.Image == nil && r.ImageProvider == nil {
+		return ErrNilEditImage
+	}
+	return nil
+}
+
+// Validate checks that the variation has an image and that its parameters are supported by its model.
+func (r ImageVariRequest) Validate() error {
+	if err := validateImageN(r.Model, r.N); err != nil {
+		return err
+	}
+	if err := validateImageSize(r.Model, r.Size); err != nil {
+		return err
+	}
+	if r.Image == nil {
+		return ErrNilVariationImage
+	}
+	return nil
+}
+
+// PreflightImage validates any image request, an ImageRequest, ImageEditRequest,
+// MultiImageEditRequest or ImageVariRequest or a pointer to one, with the Validate method
+// of its type, without reading its images or sending anything. Errors are ValidationErrors,
+// as returned by the client methods; other types fail with ErrUnknownImageRequest.
+func PreflightImage(request any) error {
+	if v := reflect.ValueOf(request); v.Kind() == reflect.Ptr && v.IsNil() {
+		return newValidationError(fmt.Errorf("%w: nil %T", ErrUnknownImageRequest, request))
+	}
+
+	var err error
+	switch r := request.(type) {
+	case ImageRequest:
+		err = r.Validate()
+	case *ImageRequest:
+		err = r.Validate()
+	case ImageEditRequest:
+		err = r.Validate()
+	case *ImageEditRequest:
+		err = r.Validate()
+	case MultiImageEditRequest:
+		err = r.Validate()
+	case *MultiImageEditRequest:
+		err = r.Validate()
+	case ImageVariRequest:
+		err = r.Validate()
+	case *ImageVariRequest:
+		err = r.Validate()
+	default:
+		err = fmt.Errorf("%w: %T", ErrUnknownImageRequest, request)
+	}
+	return newValidationError(err)
+}
+
+// validateImageRequest validates request. In StrictOpenAI mode it also rejects fields the
+// official API does not know, and transparent backgrounds without an explicit size, since
+// some servers misbehave when they have to pick the size of a transparent image.
+func (c *Client) validateImageRequest(request ImageRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	if err := c.checkB64Images(request.Model, request.ResponseFormat, request.N); err != nil {
+		return err
+	}
+	if !c.config.StrictOpenAI {
+		return nil
+	}
+	if request.Seed != nil {
+		return fmt.Errorf("%w: seed", ErrNotOpenAIField)
+	}
+	if len(request.ExtraFields) != 0 {
+		return fmt.Errorf("%w: extra fields", ErrNotOpenAIField)
+	}
+	if request.Background == CreateImageBackgroundTransparent &&
+		(request.Size == "" || request.Size == CreateImageSizeAuto) {
+		return fmt.Errorf("%w, got %q", ErrTransparentAutoSize, request.Size)
+	}
+	return nil
+}
+
+// Validate checks that the request has a prompt and images to edit, that its parameters are
+// supported by its model and that FileNames, if set, lines up with Images.
+func (r MultiImageEditRequest) Validate() error {
+	if strings.TrimSpace(r.Prompt) == "" {
+		return ErrEmptyImagePrompt
+	}
+	if err := ValidatePromptEncoding(r.Prompt); err != nil {
+		return err
+	}
+	if len(r.Images) == 0 {
+		return ErrNoEditImages
+	}
+	for i, image := range r.Images {
+		if image == nil {
+			return fmt.Errorf("image %d: %w", i, ErrNilEditImage)
+		}
+	}
+	if len(r.FileNames) != 0 && len(r.FileNames) != len(r.Images) {
+		return fmt.Errorf("%w: %d file names for %d images", ErrMisalignedFileNames, len(r.FileNames), len(r.Images))
+	}
+	if err := validateImageN(r.Model, r.N); err != nil {
+		return err
+	}
+	if err := validateImageSize(r.Model, r.Size); err != nil {
+		return err
+	}
+	return validateImageQuality(r.Model, r.Quality)
+}
+
+// ValidatePromptEncoding returns an error wrapping ErrInvalidPromptEncoding, with the byte
+// offset of the first invalid sequence, if prompt is not valid UTF-8. Such prompts are
+// usually mojibake from decoding input with the wrong charset.
+func ValidatePromptEncoding(prompt string) error {
+	if utf8.ValidString(prompt) {
+		return nil
+	}
+	for offset := 0; offset < len(prompt); {
+		r, size := utf8.DecodeRuneInString(prompt[offset:])
+		if r == utf8.RuneError && size == 1 {
+			return fmt.Errorf("%w: invalid byte 0x%02x at offset %d", ErrInvalidPromptEncoding, prompt[offset], offset)
+		}
+		offset += size
+	}
+	return ErrInvalidPromptEncoding
+}
+
+// checkB64Images enforces ClientConfig.MaxB64Images on a request for n images, which are
+// returned as b64_json when responseFormat asks for it or the model is gpt-image-1.
+func (c *Client) checkB64Images(model, responseFormat string, n int) error {
+	if c.config.MaxB64Images <= 0 || n <= c.config.MaxB64Images {
+		return nil
+	}
+	if responseFormat != CreateImageResponseFormatB64JSON && model != CreateImageModelGptImage1 {
+		return nil
+	}
+	return fmt.Errorf("%w: n=%d exceeds the limit of %d", ErrTooManyB64Images, n, c.config.MaxB64Images)
+}
+
+// validateImageN checks n against the maximum number of images the model can return.
+// Unknown models are not validated so that OpenAI-compatible servers keep working.
+func validateImageN(model string, n int) error {
+	info, ok := lookupImageModel(model)
+	if !ok || n <= info.MaxN {
+		return nil
+	}
+	return fmt.Errorf("%w: %s supports at most n=%d, got %d", ErrImageNUnsupported, model, info.MaxN, n)
+}
+
+// validateOutputFormat checks that a known model can return images in format. Only
+// gpt-image-1 supports jpeg and webp output; dall-e models always return png.
+func validateOutputFormat(model, format string) error {
+	info, ok := lookupImageModel(model)
+	if !ok || format == "" || info.SupportsOutputFormat(format) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s only returns %s, got %q",
+		ErrUnsupportedOutputFormat, model, strings.Join(info.OutputFormats, ", "), format)
+}
+
+// validateOutputCompression checks the compression range, and that gpt-image-1 png output,
+// which is lossless, is not given a compression level.
+func validateOutputCompression(model, format string, compression int) error {
+	if compression < 0 || compression > maxOutputCompression {
+		return fmt.Errorf("%w, got %d", ErrInvalidOutputCompression, compression)
+	}
+	if compression != 0 && model == CreateImageModelGptImage1 && format == CreateImageOutputFormatPNG {
+		return fmt.Errorf("%w: use %s or %s to compress %s output",
+			ErrOutputCompressionUnsupported, CreateImageOutputFormatJPEG, CreateImageOutputFormatWEBP, model)
+	}
+	return nil
+}
+
+// validateImageQuality checks that quality belongs to the naming scheme of the model:
+// dall-e models use hd/standard while gpt-image-1 uses high/medium/low/auto.
+func validateImageQuality(model, quality string) error {
+	info, ok := lookupImageModel(model)
+	if !ok || quality == "" || info.SupportsQuality(quality) {
+		return nil
+	}
+	allowed := strings.Join(info.Qualities, ", ")
+	for _, other := range imageModels {
+		if other.SupportsQuality(quality) {
+			return fmt.Errorf("%w: %q is a %s quality, not a %s one, use one of %s",
+				ErrUnsupportedImageQuality, quality, other.Model, model, allowed)
+		}
+	}
+	return fmt.Errorf("%w: %q is not a %s quality, use one of %s", ErrUnsupportedImageQuality, quality, model, allowed)
+}
+
+// validateImageSize checks that a size requested from a known model is a well-formed size the
+// model supports. An empty size is left for the API to default.
+func validateImageSize(model, size string) error {
+	info, ok := lookupImageModel(model)
+	if !ok || size == "" {
+		return nil
+	}
+	normalized, err := NormalizeSize(size)
+	if err != nil {
+		return err
+	}
+	if !info.SupportsSize(normalized) {
+		return fmt.Errorf("%w: %s supports %s, got %s",
+			ErrUnsupportedImageSize, model, strings.Join(info.Sizes, ", "), normalized)
+	}
+	return nil
+}
+
+// checkImageContent returns ErrEmptyImage if r has no content left to read. Seekable readers
+// are measured and left at their position, so they can still be streamed. Other readers are
+// peeked by one byte, and the returned reader replays that byte before the rest of r. Nil
+// readers are returned as is.
+func checkImageContent(r io.Reader) (io.Reader, error) {
+	if r == nil {
+		return nil, nil
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		if remaining, err := remainingBytes(seeker); err == nil {
+			if remaining <= 0 {
+				return r, ErrEmptyImage
+			}
+			return r, nil
+		}
+	}
+
+	peek := make([]byte, 1)
+	n, err := io.ReadFull(r, peek)
+	if n == 0 {
+		if errors.Is(err, io.EOF) {
+			return r, ErrEmptyImage
+		}
+		return r, err
+	}
+	return io.MultiReader(bytes.NewReader(peek), r), nil
+}
+
+// checkMinDimension enforces ClientConfig.MinImageDimension on an input image, decoding only
+// its header. Seekable readers are moved back to their position; other readers are replaced
+// by one replaying the header bytes. Images in formats that cannot be decoded are passed
+// through unchecked and left to the server.
+func (c *Client) checkMinDimension(r io.Reader) (io.Reader, error) {
+	minDimension := c.config.MinImageDimension
+	if minDimension <= 0 || r == nil {
+		return r, nil
+	}
+
+	var (
+		config image.Config
+		err    error
+	)
+	if seeker, ok := r.(io.ReadSeeker); ok {
+		offset, seekErr := seeker.Seek(0, io.SeekCurrent)
+		if seekErr != nil {
+			return r, seekErr
+		}
+		config, _, err = image.DecodeConfig(seeker)
+		if _, seekErr = seeker.Seek(offset, io.SeekStart); seekErr != nil {
+			return r, seekErr
+		}
+	} else {
+		header := &bytes.Buffer{}
+		config, _, err = image.DecodeConfig(io.TeeReader(r, header))
+		r = io.MultiReader(header, r)
+	}
+	if err != nil {
+		return r, nil
+	}
+	if config.Width < minDimension || config.Height < minDimension {
+		return r, fmt.Errorf("%w: image is %dx%d, at least %dx%d is required",
+			ErrImageTooSmall, config.Width, config.Height, minDimension, minDimension)
+	}
+	return r, nil
+}
+
+// remainingBytes returns the number of bytes between the current position of s and its end.
+func remainingBytes(s io.Seeker) (int64, error) {
+	current, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, err
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.Seek(current, io.SeekStart)
+	return end - current, err
+}
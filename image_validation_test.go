@@ -0,0 +1,369 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestImageRequestValidateOutputCompression(t *testing.T) {
+	request := openai.ImageRequest{
+		Model:             openai.CreateImageModelGptImage1,
+		OutputFormat:      openai.CreateImageOutputFormatPNG,
+		OutputCompression: 50,
+	}
+	checks.ErrorIs(t, request.Validate(), openai.ErrOutputCompressionUnsupported,
+		"png output should reject compression on gpt-image-1")
+
+	request.OutputFormat = openai.CreateImageOutputFormatWEBP
+	checks.NoError(t, request.Validate(), "webp output should accept compression")
+
+	request.OutputFormat = openai.CreateImageOutputFormatPNG
+	request.OutputCompression = 0
+	checks.NoError(t, request.Validate(), "png output without compression should be valid")
+
+	request.OutputFormat = openai.CreateImageOutputFormatJPEG
+	request.OutputCompression = 101
+	checks.ErrorIs(t, request.Validate(), openai.ErrInvalidOutputCompression,
+		"compression above 100 should be rejected")
+}
+
+func TestImageRequestValidateOutputFormat(t *testing.T) {
+	request := openai.ImageRequest{
+		Model:        openai.CreateImageModelDallE3,
+		OutputFormat: openai.CreateImageOutputFormatWEBP,
+	}
+	checks.ErrorIs(t, request.Validate(), openai.ErrUnsupportedOutputFormat, "dall-e-3 should reject webp output")
+
+	request.OutputFormat = openai.CreateImageOutputFormatJPEG
+	checks.ErrorIs(t, request.Validate(), openai.ErrUnsupportedOutputFormat, "dall-e-3 should reject jpeg output")
+
+	request.Model = openai.CreateImageModelGptImage1
+	request.OutputFormat = openai.CreateImageOutputFormatWEBP
+	checks.NoError(t, request.Validate(), "gpt-image-1 should accept webp output")
+
+	request.Model = "custom-model"
+	checks.NoError(t, request.Validate(), "unknown models should not be validated")
+}
+
+func TestImageRequestValidateQuality(t *testing.T) {
+	cases := []struct {
+		model   string
+		quality string
+		valid   bool
+	}{
+		{openai.CreateImageModelDallE3, openai.CreateImageQualityHD, true},
+		{openai.CreateImageModelDallE3, openai.CreateImageQualityStandard, true},
+		{openai.CreateImageModelDallE3, openai.CreateImageQualityHigh, false},
+		{openai.CreateImageModelDallE3, openai.CreateImageQualityLow, false},
+		{openai.CreateImageModelDallE2, openai.CreateImageQualityStandard, true},
+		{openai.CreateImageModelDallE2, openai.CreateImageQualityHD, false},
+		{openai.CreateImageModelGptImage1, openai.CreateImageQualityHigh, true},
+		{openai.CreateImageModelGptImage1, openai.CreateImageQualityMedium, true},
+		{openai.CreateImageModelGptImage1, openai.CreateImageQualityLow, true},
+		{openai.CreateImageModelGptImage1, openai.CreateImageQualityAuto, true},
+		{openai.CreateImageModelDallE3, openai.CreateImageQualityAuto, false},
+		{openai.CreateImageModelGptImage1, openai.CreateImageQualityHD, false},
+		{openai.CreateImageModelGptImage1, openai.CreateImageQualityStandard, false},
+		{openai.CreateImageModelGptImage1, "", true},
+		{"custom-model", "ultra", true},
+	}
+	for _, tc := range cases {
+		err := openai.ImageRequest{Model: tc.model, Quality: tc.quality}.Validate()
+		if tc.valid {
+			checks.NoError(t, err, tc.model+" should accept quality "+tc.quality)
+		} else {
+			checks.ErrorIs(t, err, openai.ErrUnsupportedImageQuality, tc.model+" should reject quality "+tc.quality)
+		}
+	}
+
+	err := openai.ImageRequest{Model: openai.CreateImageModelGptImage1, Quality: openai.CreateImageQualityHD}.Validate()
+	if err == nil || !strings.Contains(err.Error(), "high, medium, low, auto") {
+		t.Errorf("expected the error to name the gpt-image-1 qualities, got %v", err)
+	}
+}
+
+func TestImageRequestValidateNormalizesSize(t *testing.T) {
+	request := openai.ImageRequest{Model: openai.CreateImageModelDallE3, Size: "1792 X 1024"}
+	checks.NoError(t, request.Validate(), "sizes should be validated after normalization")
+
+	request.Size = "1024 by 1024"
+	checks.ErrorIs(t, request.Validate(), openai.ErrInvalidImageSize, "malformed sizes should be rejected")
+}
+
+func TestMultiImageEditRequestValidate(t *testing.T) {
+	valid := func() openai.MultiImageEditRequest {
+		return openai.MultiImageEditRequest{
+			Images: []io.Reader{strings.NewReader("first"), strings.NewReader("second")},
+			Prompt: "There is a turtle in the pool",
+			Model:  openai.CreateImageModelGptImage1,
+		}
+	}
+	checks.NoError(t, valid().Validate(), "a complete request should be valid")
+
+	cases := []struct {
+		name   string
+		modify func(*openai.MultiImageEditRequest)
+		err    error
+	}{
+		{"empty prompt", func(r *openai.MultiImageEditRequest) { r.Prompt = " " }, openai.ErrEmptyImagePrompt},
+		{"no images", func(r *openai.MultiImageEditRequest) { r.Images = nil }, openai.ErrNoEditImages},
+		{"nil image", func(r *openai.MultiImageEditRequest) { r.Images[1] = nil }, openai.ErrNilEditImage},
+		{"misaligned file names", func(r *openai.MultiImageEditRequest) {
+			r.FileNames = []string{"first.png"}
+		}, openai.ErrMisalignedFileNames},
+		{"invalid size", func(r *openai.MultiImageEditRequest) { r.Size = "big" }, openai.ErrInvalidImageSize},
+		{"invalid quality", func(r *openai.MultiImageEditRequest) {
+			r.Quality = openai.CreateImageQualityHD
+		}, openai.ErrUnsupportedImageQuality},
+		{"too many images", func(r *openai.MultiImageEditRequest) { r.N = 11 }, openai.ErrImageNUnsupported},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := valid()
+			tc.modify(&request)
+			checks.ErrorIs(t, request.Validate(), tc.err, "unexpected validation result")
+		})
+	}
+}
+
+func TestStrictOpenAI(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.StrictOpenAI = true
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleImageEndpoint)
+	ctx := context.Background()
+
+	seed := 42
+	_, err := client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum", Seed: &seed})
+	checks.ErrorIs(t, err, openai.ErrNotOpenAIField, "seed should be rejected in strict mode")
+
+	_, err = client.CreateImage(ctx, openai.ImageRequest{
+		Prompt:      "Lorem ipsum",
+		ExtraFields: map[string]any{"steps": 30},
+	})
+	checks.ErrorIs(t, err, openai.ErrNotOpenAIField, "extra fields should be rejected in strict mode")
+
+	_, err = client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "official fields should be accepted in strict mode")
+
+	transparent := openai.ImageRequest{
+		Prompt:     "Lorem ipsum",
+		Model:      openai.CreateImageModelGptImage1,
+		Background: openai.CreateImageBackgroundTransparent,
+		Size:       openai.CreateImageSizeAuto,
+	}
+	_, err = client.CreateImage(ctx, transparent)
+	checks.ErrorIs(t, err, openai.ErrTransparentAutoSize, "transparent auto sized images should be rejected")
+
+	transparent.Size = openai.CreateImageSize1024x1024
+	_, err = client.CreateImage(ctx, transparent)
+	checks.NoError(t, err, "transparent images with an explicit size should be accepted")
+}
+
+func TestImageRequestExtraFields(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var body map[string]any
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "could not read request")
+		fmt.Fprintln(w, `{"data":[]}`)
+	})
+
+	seed := 42
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{
+		Prompt:      "Lorem ipsum",
+		Seed:        &seed,
+		ExtraFields: map[string]any{"steps": 30, "prompt": "overridden"},
+	})
+	checks.NoError(t, err, "CreateImage error")
+	if body["seed"] != float64(42) || body["steps"] != float64(30) || body["prompt"] != "Lorem ipsum" {
+		t.Errorf("unexpected request body %v", body)
+	}
+}
+
+func TestPreflightImage(t *testing.T) {
+	cases := []struct {
+		name    string
+		request any
+		wantErr error
+	}{
+		{"generation", openai.ImageRequest{Model: openai.CreateImageModelDallE3}, nil},
+		{"generation pointer", &openai.ImageRequest{Model: openai.CreateImageModelDallE3, N: 2},
+			openai.ErrImageNUnsupported},
+		{"edit", openai.ImageEditRequest{Model: openai.CreateImageModelDallE2, Quality: openai.CreateImageQualityHigh},
+			openai.ErrUnsupportedImageQuality},
+		{"multi edit", openai.MultiImageEditRequest{Prompt: "Lorem ipsum"}, openai.ErrNoEditImages},
+		{"variation", openai.ImageVariRequest{Model: openai.CreateImageModelDallE2, N: 11},
+			openai.ErrImageNUnsupported},
+		{"edit without image", openai.ImageEditRequest{Prompt: "Lorem ipsum"}, openai.ErrNilEditImage},
+		{"edit with image provider", openai.ImageEditRequest{
+			Prompt:        "Lorem ipsum",
+			ImageProvider: func() (io.Reader, error) { return bytes.NewReader([]byte("fake image")), nil },
+		}, nil},
+		{"variation without image", openai.ImageVariRequest{Model: openai.CreateImageModelDallE2},
+			openai.ErrNilVariationImage},
+		{"variation pointer", &openai.ImageVariRequest{
+			Model: openai.CreateImageModelDallE2,
+			Image: bytes.NewReader([]byte("fake image")),
+		}, nil},
+		{"unknown type", openai.ChatCompletionRequest{}, openai.ErrUnknownImageRequest},
+		{"nil pointer", (*openai.ImageRequest)(nil), openai.ErrUnknownImageRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := openai.PreflightImage(tc.request)
+			if tc.wantErr == nil {
+				checks.NoError(t, err, "PreflightImage error")
+				return
+			}
+			checks.ErrorIs(t, err, tc.wantErr, "PreflightImage should fail")
+			if openai.ErrorKindOf(err) != openai.KindValidation {
+				t.Errorf("expected a validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestMaxB64Images(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxB64Images = 2
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleImageEndpoint)
+	ctx := context.Background()
+
+	request := openai.ImageRequest{
+		Prompt:         "Lorem ipsum",
+		Model:          openai.CreateImageModelDallE2,
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+		N:              3,
+	}
+	_, err := client.CreateImage(ctx, request)
+	checks.ErrorIs(t, err, openai.ErrTooManyB64Images, "b64_json requests above the limit should be rejected")
+
+	request.N = 2
+	_, err = client.CreateImage(ctx, request)
+	checks.NoError(t, err, "b64_json requests within the limit should be sent")
+
+	request.N = 3
+	request.ResponseFormat = openai.CreateImageResponseFormatURL
+	_, err = client.CreateImage(ctx, request)
+	checks.NoError(t, err, "url requests should not be limited")
+
+	_, err = client.CreateVariImage(ctx, openai.ImageVariRequest{
+		Image:          bytes.NewReader([]byte("image")),
+		ResponseFormat: openai.CreateImageResponseFormatB64JSON,
+		N:              3,
+	})
+	checks.ErrorIs(t, err, openai.ErrTooManyB64Images, "variations above the limit should be rejected")
+}
+
+func TestValidatePromptEncoding(t *testing.T) {
+	checks.NoError(t, openai.ValidatePromptEncoding("a café at dusk, 夕暮れ"), "expected a valid UTF-8 prompt to pass")
+
+	err := openai.ValidatePromptEncoding("a caf\xe9 at dusk")
+	checks.ErrorIs(t, err, openai.ErrInvalidPromptEncoding, "expected ErrInvalidPromptEncoding")
+	if !strings.Contains(err.Error(), "offset 5") {
+		t.Errorf("expected the error to report offset 5, got %v", err)
+	}
+
+	err = openai.ImageRequest{Prompt: "a caf\xe9"}.Validate()
+	checks.ErrorIs(t, err, openai.ErrInvalidPromptEncoding, "expected Validate to reject the prompt")
+}
+
+func TestMinImageDimension(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MinImageDimension = 16
+	})
+	defer teardown()
+	var uploaded []byte
+	server.RegisterHandler("/v1/images/variations", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		uploaded, _ = io.ReadAll(file)
+		handleVariateImageEndpoint(w, r)
+	})
+
+	small := encodeTestPNG(t, 8, 32)
+	_, err := client.CreateVariImage(context.Background(), openai.ImageVariRequest{
+		Image: bytes.NewReader(small),
+		N:     1,
+	})
+	checks.ErrorIs(t, err, openai.ErrImageTooSmall, "expected an 8x32 image to be rejected")
+	if err != nil && !strings.Contains(err.Error(), "8x32") {
+		t.Errorf("expected the error to name the image dimensions, got %v", err)
+	}
+
+	// Non-seekable inputs are replayed in full after their header is decoded.
+	large := encodeTestPNG(t, 16, 16)
+	_, err = client.CreateVariImage(context.Background(), openai.ImageVariRequest{
+		Image: io.MultiReader(bytes.NewReader(large)),
+		N:     1,
+	})
+	checks.NoError(t, err, "CreateVariImage error")
+	if !bytes.Equal(uploaded, large) {
+		t.Error("expected the full image to be uploaded")
+	}
+}
+
+func TestImageRequestValidateSizeForModel(t *testing.T) {
+	cases := []struct {
+		model, size string
+		ok          bool
+	}{
+		{openai.CreateImageModelDallE2, openai.CreateImageSize512x512, true},
+		{openai.CreateImageModelDallE2, openai.CreateImageSize1792x1024, false},
+		{openai.CreateImageModelDallE3, "1024 x 1792", true},
+		{openai.CreateImageModelDallE3, openai.CreateImageSize1536x1024, false},
+		{openai.CreateImageModelDallE3, openai.CreateImageSize256x256, false},
+		{openai.CreateImageModelGptImage1, openai.CreateImageSize1024x1536, true},
+		{openai.CreateImageModelGptImage1, openai.CreateImageSize512x512, false},
+		{openai.CreateImageModelGptImage1, "", true},
+	}
+	for _, tc := range cases {
+		err := openai.ImageRequest{Prompt: "Lorem ipsum", Model: tc.model, Size: tc.size}.Validate()
+		if tc.ok {
+			checks.NoError(t, err, fmt.Sprintf("%s should support %q", tc.model, tc.size))
+		} else {
+			checks.ErrorIs(t, err, openai.ErrUnsupportedImageSize, fmt.Sprintf("%s should reject %q", tc.model, tc.size))
+		}
+	}
+}
+
+func TestCreateImageRejectsUnsupportedSize(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	called := false
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		handleImageEndpoint(w, r)
+	})
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{
+		Prompt: "Lorem ipsum",
+		Model:  openai.CreateImageModelDallE2,
+		Size:   openai.CreateImageSize1792x1024,
+	})
+	checks.ErrorIs(t, err, openai.ErrUnsupportedImageSize, "expected the size to be rejected")
+	if err != nil && !strings.Contains(err.Error(), "256x256, 512x512, 1024x1024") {
+		t.Errorf("expected the error to list the supported sizes, got %v", err)
+	}
+	if called {
+		t.Error("no request should be sent for an unsupported size")
+	}
+}
@@ -0,0 +1,185 @@
+//go:build webp
+
+package openai
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"io"
+	"math/bits"
+)
+
+const (
+	// vp8lMaxDimension is the largest width or height of a lossless webp image.
+	vp8lMaxDimension = 1 << 14
+	// vp8lGreenAlphabet is the size of the green alphabet without color cache: 256 literals
+	// followed by 24 backward reference length prefixes.
+	vp8lGreenAlphabet = 256 + 24
+	// vp8lLiteralCodeLength is the code length of every literal of a channel with several values.
+	vp8lLiteralCodeLength = 8
+)
+
+// vp8lCodeLengthCodeOrder is the order in which the code lengths of the code length code are stored.
+var vp8lCodeLengthCodeOrder = [...]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// encodeWebP writes img to w as a lossless webp image. It favors simplicity over size: pixels
+// are stored as literals without transforms or backward references, so the output is about
+// as large as the raw pixels, minus the channels that hold a single value.
+func encodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return fmt.Errorf("%w: webp images are 1 to %d pixels wide and high, got %dx%d",
+			ErrUnsupportedImageFormat, vp8lMaxDimension, width, height)
+	}
+
+	nrgba := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+
+	// Channels in the order of their prefix codes: green, red, blue, alpha.
+	channels := [4]func(c color.NRGBA) uint8{
+		func(c color.NRGBA) uint8 { return c.G },
+		func(c color.NRGBA) uint8 { return c.R },
+		func(c color.NRGBA) uint8 { return c.B },
+		func(c color.NRGBA) uint8 { return c.A },
+	}
+	var constant [4]bool
+	first := nrgba.NRGBAAt(0, 0)
+	for i, channel := range channels {
+		constant[i] = true
+		for y := 0; y < height && constant[i]; y++ {
+			for x := 0; x < width; x++ {
+				if channel(nrgba.NRGBAAt(x, y)) != channel(first) {
+					constant[i] = false
+					break
+				}
+			}
+		}
+	}
+	alphaUsed := !constant[3] || first.A != 0xff
+
+	bw := &vp8lBitWriter{}
+	bw.writeBits(width-1, 14)
+	bw.writeBits(height-1, 14)
+	bw.writeBool(alphaUsed)
+	bw.writeBits(0, 3)  // version
+	bw.writeBool(false) // no transform
+	bw.writeBool(false) // no color cache
+	bw.writeBool(false) // a single group of prefix codes
+
+	alphabets := [4]int{vp8lGreenAlphabet, 256, 256, 256}
+	for i, channel := range channels {
+		if constant[i] {
+			bw.writeSingleSymbolCode(int(channel(first)))
+		} else {
+			bw.writeLiteralCode(alphabets[i])
+		}
+	}
+	// Backward references are never used, so the distance code is a single unused symbol.
+	bw.writeSingleSymbolCode(0)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := nrgba.NRGBAAt(x, y)
+			for i, channel := range channels {
+				if !constant[i] {
+					// Huffman codes are stored starting with their most significant bit.
+					bw.writeBits(int(bits.Reverse8(channel(c))), vp8lLiteralCodeLength)
+				}
+			}
+		}
+	}
+	payload := bw.bytes()
+
+	// The VP8L chunk holds the signature byte and the payload, padded to an even size.
+	chunkSize := 1 + len(payload)
+	padding := chunkSize % 2
+	header := []byte("RIFF\x00\x00\x00\x00WEBPVP8L\x00\x00\x00\x00\x2f")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+chunkSize+padding))
+	binary.LittleEndian.PutUint32(header[16:], uint32(chunkSize))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	if padding != 0 {
+		_, err := w.Write([]byte{0})
+		return err
+	}
+	return nil
+}
+
+// vp8lBitWriter packs values least significant bit first, as VP8L streams are read.
+type vp8lBitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (b *vp8lBitWriter) writeBits(value, n int) {
+	b.acc |= uint64(value) << b.nbits
+	b.nbits += uint(n)
+	for b.nbits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+func (b *vp8lBitWriter) writeBool(value bool) {
+	if value {
+		b.writeBits(1, 1)
+	} else {
+		b.writeBits(0, 1)
+	}
+}
+
+// bytes returns the written bits, with the last byte padded with zeros.
+func (b *vp8lBitWriter) bytes() []byte {
+	if b.nbits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nbits = 0, 0
+	}
+	return b.buf
+}
+
+// writeSingleSymbolCode writes a simple prefix code with a single 8-bit symbol, which takes
+// no bits to read.
+func (b *vp8lBitWriter) writeSingleSymbolCode(symbol int) {
+	b.writeBool(true) // simple code
+	b.writeBits(0, 1) // one symbol
+	b.writeBool(true) // 8-bit symbol
+	b.writeBits(symbol, 8)
+}
+
+// writeLiteralCode writes a normal prefix code giving the 256 literals of an alphabet the
+// same code length, and none to the other symbols, so that literal s is coded as s.
+func (b *vp8lBitWriter) writeLiteralCode(alphabet int) {
+	b.writeBool(false) // normal code
+
+	// The code length code only needs lengths 0 and 8, both coded with a single bit:
+	// 0 as "0" and 8 as "1".
+	var lengths [len(vp8lCodeLengthCodeOrder)]int
+	lengths[0] = 1
+	lengths[vp8lLiteralCodeLength] = 1
+	count := 0
+	for i, symbol := range vp8lCodeLengthCodeOrder {
+		if lengths[symbol] != 0 {
+			count = i + 1
+		}
+	}
+	b.writeBits(count-4, 4)
+	for _, symbol := range vp8lCodeLengthCodeOrder[:count] {
+		b.writeBits(lengths[symbol], 3)
+	}
+
+	b.writeBool(false) // code lengths for the whole alphabet
+	for symbol := 0; symbol < alphabet; symbol++ {
+		b.writeBool(symbol < 256)
+	}
+}
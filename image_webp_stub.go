@@ -0,0 +1,14 @@
+//go:build !webp
+
+package openai
+
+import (
+	"fmt"
+	"image"
+	"io"
+)
+
+// encodeWebP fails without the webp build tag, which adds the lossless webp encoder.
+func encodeWebP(io.Writer, image.Image) error {
+	return fmt.Errorf("%w: webp encoding needs the webp build tag", ErrUnsupportedImageFormat)
+}
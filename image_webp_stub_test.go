@@ -0,0 +1,15 @@
+//go:build !webp
+
+package openai_test
+
+import (
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestConvertImageWebPNeedsBuildTag(t *testing.T) {
+	_, err := openai.ConvertImage(encodeTestPNG(t, 8, 4), openai.CreateImageOutputFormatWEBP, 0)
+	checks.ErrorIs(t, err, openai.ErrUnsupportedImageFormat, "webp encoding needs the webp build tag")
+}
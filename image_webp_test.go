@@ -0,0 +1,32 @@
+//go:build webp
+
+package openai_test
+
+import (
+	"encoding/binary"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestConvertImageWebP(t *testing.T) {
+	converted, err := openai.ConvertImage(encodeTestPNG(t, 8, 4), openai.CreateImageOutputFormatWEBP, 0)
+	checks.NoError(t, err, "ConvertImage to webp error")
+	if contentType := http.DetectContentType(converted); contentType != "image/webp" {
+		t.Fatalf("expected webp output, got %s", contentType)
+	}
+	if size := binary.LittleEndian.Uint32(converted[4:]); int(size) != len(converted)-8 {
+		t.Errorf("expected a RIFF size of %d, got %d", len(converted)-8, size)
+	}
+	if chunk := string(converted[12:16]); chunk != "VP8L" {
+		t.Fatalf("expected a lossless VP8L chunk, got %q", chunk)
+	}
+
+	// The VP8L header follows the signature byte: 14 bits of width-1, then 14 of height-1.
+	header := binary.LittleEndian.Uint32(converted[21:])
+	if width, height := header&0x3fff+1, header>>14&0x3fff+1; width != 8 || height != 4 {
+		t.Errorf("expected dimensions to be preserved, got %dx%d", width, height)
+	}
+}
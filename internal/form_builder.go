@@ -8,6 +8,7 @@ import (
 	"net/textproto"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -50,8 +51,52 @@ func (fb *DefaultFormBuilder) CreateFormFileReader(fieldname string, r io.Reader
 // CreateFormFileReaderWithContentType creates a form field with a file reader and a content type.
 // The filename in parameters can be an empty string.
 // The filename in Content-Disposition is required, But it can be an empty string.
-// The contentType is validated and set in the header. If the contentType is not valid, it will not be set in the header.
+// The contentType is normalized with the filename, see normalizeContentType, and set in the header.
+// If no content type can be determined, it will not be set in the header.
 func (fb *DefaultFormBuilder) CreateFormFileReaderWithContentType(fieldname string, r io.Reader, filename, contentType string) error {
+	fieldWriter, err := fb.writer.CreatePart(fileHeader(fieldname, filename, contentType))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(fieldWriter, r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CreateFormFileReaderWithLength is like CreateFormFileReaderWithContentType, but the part
+// advertises length in a Content-Length header, for servers that reject parts of unknown length.
+// Only the first length bytes of r are written; io.ErrUnexpectedEOF is returned if r has fewer.
+func (fb *DefaultFormBuilder) CreateFormFileReaderWithLength(
+	fieldname string,
+	r io.Reader,
+	filename, contentType string,
+	length int64,
+) error {
+	h := fileHeader(fieldname, filename, contentType)
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+
+	fieldWriter, err := fb.writer.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	n, err := io.Copy(fieldWriter, io.LimitReader(r, length))
+	if err != nil {
+		return err
+	}
+	if n < length {
+		return fmt.Errorf("%w: read %d of %d bytes", io.ErrUnexpectedEOF, n, length)
+	}
+
+	return nil
+}
+
+// fileHeader returns the part header of a form file, see CreateFormFileReaderWithContentType.
+func fileHeader(fieldname, filename, contentType string) textproto.MIMEHeader {
 	h := make(textproto.MIMEHeader)
 	h.Set(
 		"Content-Disposition",
@@ -62,24 +107,25 @@ func (fb *DefaultFormBuilder) CreateFormFileReaderWithContentType(fieldname stri
 		),
 	)
 
-	// Validate the contentType.
-	// Note: The 'mime' package (import "mime") must be imported in your Go file.
-	mediaType, _, err := mime.ParseMediaType(contentType)
-	if err == nil {
+	if mediaType := normalizeContentType(contentType, filename); mediaType != "" {
 		h.Set("Content-Type", mediaType)
 	}
+	return h
+}
 
-	fieldWriter, err := fb.writer.CreatePart(h)
-	if err != nil {
-		return err
+// normalizeContentType returns the media type of a form file: ct without its parameters when
+// it is a valid media type, otherwise the type registered for the extension of filename, or
+// an empty string if neither is known.
+func normalizeContentType(ct, filename string) string {
+	if mediaType, _, err := mime.ParseMediaType(ct); err == nil {
+		return mediaType
 	}
-
-	_, err = io.Copy(fieldWriter, r)
-	if err != nil {
-		return err
+	if ext := filepath.Ext(filename); ext != "" {
+		if mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext)); err == nil {
+			return mediaType
+		}
 	}
-
-	return nil
+	return ""
 }
 
 func (fb *DefaultFormBuilder) createFormFile(fieldname string, r io.Reader, filename string) error {
@@ -108,6 +154,12 @@ func (fb *DefaultFormBuilder) Close() error {
 	return fb.writer.Close()
 }
 
+// SetBoundary overrides the random boundary, so that a form can be rebuilt identically.
+// It must be called before any field is written.
+func (fb *DefaultFormBuilder) SetBoundary(boundary string) error {
+	return fb.writer.SetBoundary(boundary)
+}
+
 func (fb *DefaultFormBuilder) FormDataContentType() string {
 	return fb.writer.FormDataContentType()
 }
@@ -5,7 +5,11 @@ import (
 
 	"bytes"
 	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -72,3 +76,52 @@ func TestFormBuilderWithReader(t *testing.T) {
 	err = builder.CreateFormFileReader("file", successReader, "")
 	checks.NoError(t, err, "formbuilder should not return error")
 }
+
+func TestNormalizeContentType(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		filename    string
+		want        string
+	}{
+		{"inferred png", "", "cat.png", "image/png"},
+		{"inferred uppercase extension", "", "cat.JPEG", "image/jpeg"},
+		{"inferred webp", "", "dir/cat.webp", "image/webp"},
+		{"explicit", "image/webp", "cat.png", "image/webp"},
+		{"explicit parameters dropped", "image/png; name=cat", "", "image/png"},
+		{"invalid falls back to extension", "not a type", "cat.png", "image/png"},
+		{"unknown", "", "cat", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := normalizeContentType(tc.contentType, tc.filename); got != tc.want {
+				t.Errorf("normalizeContentType(%q, %q) = %q, want %q", tc.contentType, tc.filename, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFormBuilderWithLength(t *testing.T) {
+	body := &bytes.Buffer{}
+	builder := NewFormBuilder(body)
+	err := builder.CreateFormFileReaderWithLength("image", strings.NewReader("0123456789"), "", "image/png", 4)
+	checks.NoError(t, err, "formbuilder should not return error")
+	checks.NoError(t, builder.Close(), "formbuilder close should not return error")
+
+	_, params, err := mime.ParseMediaType(builder.FormDataContentType())
+	checks.NoError(t, err, "parse content type error")
+	part, err := multipart.NewReader(body, params["boundary"]).NextPart()
+	checks.NoError(t, err, "read part error")
+	if got := part.Header.Get("Content-Length"); got != "4" {
+		t.Errorf("expected Content-Length 4, got %q", got)
+	}
+	data, err := io.ReadAll(part)
+	checks.NoError(t, err, "read part body error")
+	if string(data) != "0123" {
+		t.Errorf("expected the part to be cut at its length, got %q", data)
+	}
+
+	builder = NewFormBuilder(&bytes.Buffer{})
+	err = builder.CreateFormFileReaderWithLength("image", strings.NewReader("01"), "", "image/png", 4)
+	checks.ErrorIs(t, err, io.ErrUnexpectedEOF, "formbuilder should return error if the reader is short")
+}
@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"io"
+	"net/http"
+)
+
+// Metrics receives counters from the client, for example to back them with Prometheus
+// or expvar. Set it as ClientConfig.Metrics; the client records nothing when it is nil.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// CountRequest is called for every HTTP attempt, including retries.
+	CountRequest(method, path string)
+	// CountError is called for every failed attempt with its status code,
+	// or zero when no response was received.
+	CountError(method, path string, statusCode int)
+	// CountRetry is called before every retry.
+	CountRetry(method, path string)
+	// AddBytesUploaded is called with the size of every request body that has a known length.
+	AddBytesUploaded(n int64)
+	// AddBytesDownloaded is called as response bodies are read.
+	AddBytesDownloaded(n int64)
+}
+
+// recordAttempt reports an HTTP attempt and its outcome to the metrics sink, if any.
+// It returns the response with its body wrapped to count downloaded bytes.
+func (c *Client) recordAttempt(req *http.Request, resp *http.Response, err error) *http.Response {
+	metrics := c.config.Metrics
+	if metrics == nil {
+		return resp
+	}
+
+	metrics.CountRequest(req.Method, req.URL.Path)
+	if req.ContentLength > 0 {
+		metrics.AddBytesUploaded(req.ContentLength)
+	}
+	if err != nil {
+		metrics.CountError(req.Method, req.URL.Path, 0)
+		return resp
+	}
+	if isFailureStatusCode(resp) {
+		metrics.CountError(req.Method, req.URL.Path, resp.StatusCode)
+	}
+	resp.Body = &countingReadCloser{ReadCloser: resp.Body, add: metrics.AddBytesDownloaded}
+	return resp
+}
+
+// countingReadCloser reports the number of bytes read through it.
+type countingReadCloser struct {
+	io.ReadCloser
+	add func(n int64)
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.add(int64(n))
+	}
+	return n, err
+}
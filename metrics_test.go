@@ -0,0 +1,94 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+type fakeMetrics struct {
+	mu         sync.Mutex
+	requests   int
+	errors     map[int]int
+	retries    int
+	uploaded   int64
+	downloaded int64
+}
+
+func (m *fakeMetrics) CountRequest(string, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+}
+
+func (m *fakeMetrics) CountError(_, _ string, statusCode int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.errors == nil {
+		m.errors = make(map[int]int)
+	}
+	m.errors[statusCode]++
+}
+
+func (m *fakeMetrics) CountRetry(string, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retries++
+}
+
+func (m *fakeMetrics) AddBytesUploaded(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uploaded += n
+}
+
+func (m *fakeMetrics) AddBytesDownloaded(n int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.downloaded += n
+}
+
+func TestMetricsSuccess(t *testing.T) {
+	metrics := &fakeMetrics{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.Metrics = metrics
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleImageEndpoint)
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+
+	if metrics.requests != 1 || len(metrics.errors) != 0 || metrics.retries != 0 {
+		t.Errorf("unexpected counters: %d requests, errors %v, %d retries",
+			metrics.requests, metrics.errors, metrics.retries)
+	}
+	if metrics.uploaded <= 0 || metrics.downloaded <= 0 {
+		t.Errorf("expected traffic to be counted, got %d bytes up and %d bytes down",
+			metrics.uploaded, metrics.downloaded)
+	}
+}
+
+func TestMetricsFailure(t *testing.T) {
+	metrics := &fakeMetrics{}
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.Metrics = metrics
+		config.MaxRetries = 1
+		config.RetryBackoff = time.Millisecond
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(2))
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.HasError(t, err, "CreateImage should fail once retries are exhausted")
+
+	if metrics.requests != 2 || metrics.retries != 1 || metrics.errors[http.StatusServiceUnavailable] != 2 {
+		t.Errorf("unexpected counters: %d requests, errors %v, %d retries",
+			metrics.requests, metrics.errors, metrics.retries)
+	}
+}
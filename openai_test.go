@@ -16,6 +16,21 @@ func setupOpenAITestServer() (client *openai.Client, server *test.ServerTest, te
 	return
 }
 
+// setupOpenAITestServerWithConfig is like setupOpenAITestServer but lets the caller adjust the client config.
+func setupOpenAITestServerWithConfig(configure func(*openai.ClientConfig)) (
+	client *openai.Client, server *test.ServerTest, teardown func(),
+) {
+	server = test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	teardown = ts.Close
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	configure(&config)
+	client = openai.NewClientWithConfig(config)
+	return
+}
+
 func setupAzureTestServer() (client *openai.Client, server *test.ServerTest, teardown func()) {
 	server = test.NewTestServer()
 	ts := server.OpenAITestServer()
@@ -41,3 +41,33 @@ func newRateLimitHeaders(h http.Header) RateLimitHeaders {
 		ResetTokens:       ResetTime(h.Get("x-ratelimit-reset-tokens")),
 	}
 }
+
+// suggestedWait returns how long to wait before the next request according to the
+// Retry-After and x-ratelimit-reset-* headers. Reset times only count when a limit is
+// exhausted, or for any limit when rateLimited is set because the request got a 429.
+// Retry-After dates are relative to now.
+func suggestedWait(h http.Header, rateLimited bool, now time.Time) time.Duration {
+	if retryAfter := h.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds > 0 {
+			return time.Duration(seconds) * time.Second
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			if wait := date.Sub(now); wait > 0 {
+				return wait
+			}
+		}
+	}
+
+	var wait time.Duration
+	for _, limit := range []string{"requests", "tokens"} {
+		remaining := h.Get("x-ratelimit-remaining-" + limit)
+		if !rateLimited && remaining != "0" {
+			continue
+		}
+		reset, err := time.ParseDuration(h.Get("x-ratelimit-reset-" + limit))
+		if err == nil && reset > wait {
+			wait = reset
+		}
+	}
+	return wait
+}
@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+)
+
+// redactedError replaces the message of an error that contained a secret, while still
+// unwrapping to the original error.
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// secrets returns the values redacted from the errors and debug information of a call: the
+// API key of the client, the key the call is made with and ClientConfig.RedactStrings.
+func (c *Client) secrets(ctx context.Context) []string {
+	candidates := append([]string{c.apiKey(ctx), c.config.authToken}, c.config.RedactStrings...)
+	secrets := candidates[:0]
+	for _, secret := range candidates {
+		if secret != "" && !containsString(secrets, secret) {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// redactSecrets replaces every occurrence of a secret in s.
+func redactSecrets(s string, secrets []string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redactedHeaderValue)
+	}
+	return s
+}
+
+// redactError removes the secrets of the call from err. The messages of API and request
+// errors are scrubbed in place, so that they are also clean when extracted with errors.As,
+// and any other error mentioning a secret is wrapped with a redacted message.
+func (c *Client) redactError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	secrets := c.secrets(ctx)
+	if len(secrets) == 0 {
+		return err
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		apiErr.Message = redactSecrets(apiErr.Message, secrets)
+		if apiErr.Param != nil {
+			param := redactSecrets(*apiErr.Param, secrets)
+			apiErr.Param = &param
+		}
+	}
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) {
+		for _, secret := range secrets {
+			reqErr.Body = bytes.ReplaceAll(reqErr.Body, []byte(secret), []byte(redactedHeaderValue))
+		}
+	}
+
+	message := err.Error()
+	if redacted := redactSecrets(message, secrets); redacted != message {
+		return &redactedError{message: redacted, err: err}
+	}
+	return err
+}
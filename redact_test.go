@@ -0,0 +1,64 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+)
+
+// leakingDoer fails every request with an error quoting the Authorization header.
+type leakingDoer struct{}
+
+func (leakingDoer) Do(req *http.Request) (*http.Response, error) {
+	return nil, fmt.Errorf("proxy rejected %q", req.Header.Get("Authorization"))
+}
+
+func TestRedactAPIKeyInTransportErrors(t *testing.T) {
+	var hookErr error
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.HTTPClient = leakingDoer{}
+	config.DebugHook = func(info openai.RequestDebugInfo) {
+		hookErr = info.Err
+	}
+	client := openai.NewClientWithConfig(config)
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if err == nil {
+		t.Fatal("expected the request to fail")
+	}
+	for name, e := range map[string]error{"returned error": err, "debug hook error": hookErr} {
+		if e == nil || strings.Contains(e.Error(), test.GetTestToken()) || !strings.Contains(e.Error(), "[REDACTED]") {
+			t.Errorf("%s: expected the API key to be redacted, got %v", name, e)
+		}
+	}
+}
+
+func TestRedactAPIKeyInAPIErrors(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.RedactStrings = []string{"org-secret"}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"error":{"message":"Incorrect API key provided: %s for org-secret","type":"invalid_request_error"}}`,
+			test.GetTestToken())
+	})
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an *APIError, got %v", err)
+	}
+	if apiErr.Message != "Incorrect API key provided: [REDACTED] for [REDACTED]" {
+		t.Errorf("expected the key and the configured strings to be redacted, got %q", apiErr.Message)
+	}
+	if strings.Contains(err.Error(), test.GetTestToken()) {
+		t.Errorf("the error leaks the API key: %v", err)
+	}
+}
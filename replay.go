@@ -0,0 +1,144 @@
+package openai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNoRecording = errors.New("no recorded response for request")
+
+// recordedMultipartBoundary replaces the random multipart boundary of a request before it is
+// hashed, so that the same form always has the same key.
+const recordedMultipartBoundary = "recorded-boundary"
+
+// recordedResponse is the on-disk form of a response captured by RecordingTransport.
+type recordedResponse struct {
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// RecordingTransport is an http.RoundTripper that forwards requests to Transport and saves
+// every response to Dir, one JSON file per request named after its hash. The hash covers the
+// method, path, query and body of the request, but not its headers, so API keys are never
+// written to disk. Use it as the transport of ClientConfig.HTTPClient against the real API,
+// then serve the recordings back with ReplayTransport.
+type RecordingTransport struct {
+	Dir string
+	// Transport sends the requests, defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := recordingKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	data, err := json.Marshal(recordedResponse{
+		Method:     req.Method,
+		Path:       req.URL.Path,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(filepath.Join(t.Dir, key+".json"), data, 0o600); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReplayTransport is an http.RoundTripper that answers requests with the responses recorded
+// by RecordingTransport in Dir, without any network access. Requests that were not recorded
+// fail with ErrNoRecording.
+type ReplayTransport struct {
+	Dir string
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := recordingKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(t.Dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s %s", ErrNoRecording, req.Method, req.URL.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var recorded recordedResponse
+	if err = json.Unmarshal(data, &recorded); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header,
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// recordingKey hashes the method, path, query and body of req. The body is read and replaced
+// so that the request can still be sent.
+func recordingKey(req *http.Request) (string, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	normalized := body
+	if _, params, err := mime.ParseMediaType(req.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+		normalized = bytes.ReplaceAll(body, []byte(params["boundary"]), []byte(recordedMultipartBoundary))
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(strings.Join([]string{req.Method, req.URL.Path, req.URL.RawQuery}, "\n")))
+	hash.Write([]byte{'\n'})
+	hash.Write(normalized)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
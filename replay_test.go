@@ -0,0 +1,39 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+func TestRecordAndReplayImageGeneration(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(handleImageEndpoint))
+	dir := t.TempDir()
+
+	config := openai.DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.HTTPClient = &http.Client{Transport: &openai.RecordingTransport{Dir: dir}}
+	request := openai.ImageRequest{Prompt: "Lorem ipsum", N: 2}
+
+	recorded, err := openai.NewClientWithConfig(config).CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error while recording")
+	ts.Close()
+
+	config.HTTPClient = &http.Client{Transport: &openai.ReplayTransport{Dir: dir}}
+	client := openai.NewClientWithConfig(config)
+	replayed, err := client.CreateImage(context.Background(), request)
+	checks.NoError(t, err, "CreateImage error while replaying")
+	if !reflect.DeepEqual(replayed.Data, recorded.Data) || replayed.Created != recorded.Created {
+		t.Errorf("expected the recorded response %+v, got %+v", recorded, replayed)
+	}
+
+	request.Prompt = "Dolor sit amet"
+	_, err = client.CreateImage(context.Background(), request)
+	checks.ErrorIs(t, err, openai.ErrNoRecording, "unrecorded requests should fail")
+}
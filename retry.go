@@ -0,0 +1,129 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const defaultRetryBackoff = 500 * time.Millisecond
+
+// doRequest sends req, retrying transport errors, 429 and 5xx responses according to the client config.
+// The response of the last attempt is returned as is, so callers handle failure status codes as usual.
+// The whole call, retries and reading the body included, is bounded by the endpoint timeout of req.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	if c.config.DryRun {
+		return c.dryRun(req)
+	}
+	ctx, cancel := c.withEndpointTimeout(req.Context(), req.URL.Path)
+	if cancel == nil {
+		return c.doRequestWithRetries(req)
+	}
+	resp, err := c.doRequestWithRetries(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// doRequestWithRetries sends req, retrying it according to the client config.
+func (c *Client) doRequestWithRetries(req *http.Request) (*http.Response, error) {
+	maxRetries, backoff := c.retryPolicy(req.Context())
+	resp, err := c.sendAttempt(req)
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if !shouldRetry(resp, err) || !canReplayBody(req) {
+			break
+		}
+
+		retryErr := err
+		if retryErr == nil {
+			retryErr = c.handleErrorResp(resp)
+			resp.Body.Close()
+		}
+
+		if c.config.Metrics != nil {
+			c.config.Metrics.CountRetry(req.Method, req.URL.Path)
+		}
+		delay := retryDelay(backoff, attempt)
+		if c.config.OnRetry != nil {
+			c.config.OnRetry(attempt, retryErr, delay)
+		}
+		if err = sleepContext(req.Context(), c.clock, delay); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+		resp, err = c.sendAttempt(req)
+	}
+	return resp, err
+}
+
+// retryPolicy returns the number of retries and the initial backoff for a call,
+// taking the client configuration and any call options set on ctx into account.
+func (c *Client) retryPolicy(ctx context.Context) (maxRetries int, backoff time.Duration) {
+	maxRetries, backoff = c.config.MaxRetries, c.config.RetryBackoff
+	options := callOptionsFromContext(ctx)
+	if options.maxRetries != nil {
+		maxRetries = *options.maxRetries
+	}
+	if options.retryBackoff != nil {
+		backoff = *options.retryBackoff
+	}
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	return maxRetries, backoff
+}
+
+// maxRetryDelay caps the exponential backoff, unless the initial backoff is longer.
+const maxRetryDelay = 5 * time.Minute
+
+// retryDelay returns the exponential backoff delay before the given 1-based retry attempt,
+// at most maxRetryDelay or backoff, whichever is longer.
+func retryDelay(backoff time.Duration, attempt int) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+	limit := maxRetryDelay
+	if backoff > limit {
+		limit = backoff
+	}
+	delay := backoff
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		return limit
+	}
+	return delay
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// canReplayBody reports whether the request body can be sent again.
+func canReplayBody(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// sleepContext waits d on clk, returning early with the context error if ctx is done.
+func sleepContext(ctx context.Context, clk clock, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-clk.After(d):
+		return nil
+	}
+}
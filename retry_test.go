@@ -0,0 +1,247 @@
+package openai_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+)
+
+// handleFlakyImageEndpoint returns a 503 for the first failures requests, then succeeds.
+func handleFlakyImageEndpoint(failures int) func(w http.ResponseWriter, r *http.Request) {
+	calls := 0
+	return func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		if calls <= failures {
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		resBytes, _ := json.Marshal(openai.ImageResponse{Created: time.Now().Unix()})
+		fmt.Fprintln(w, string(resBytes))
+	}
+}
+
+func TestRetryCallback(t *testing.T) {
+	var attempts []int
+	var delays []time.Duration
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 3
+		config.RetryBackoff = time.Millisecond
+		config.OnRetry = func(attempt int, err error, delay time.Duration) {
+			checks.HasError(t, err, "OnRetry should receive the error that triggered the retry")
+			attempts = append(attempts, attempt)
+			delays = append(delays, delay)
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(2))
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage should succeed after retries")
+
+	if len(attempts) != 2 || attempts[0] != 1 || attempts[1] != 2 {
+		t.Fatalf("expected retry attempts [1 2], got %v", attempts)
+	}
+	if delays[1] <= delays[0] {
+		t.Errorf("expected increasing delays, got %v", delays)
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	retries := 0
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 1
+		config.RetryBackoff = time.Millisecond
+		config.OnRetry = func(int, error, time.Duration) {
+			retries++
+		}
+	})
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(5))
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.HasError(t, err, "CreateImage should fail once retries are exhausted")
+	if retries != 1 {
+		t.Errorf("expected 1 retry, got %d", retries)
+	}
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/images/generations", handleFlakyImageEndpoint(1))
+
+	_, err := client.CreateImage(context.Background(), openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.HasError(t, err, "CreateImage should not retry by default")
+}
+
+func TestImageResponseSuggestedWait(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	remaining := "5"
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-ratelimit-remaining-requests", remaining)
+		w.Header().Set("x-ratelimit-reset-requests", "1.5s")
+		w.Header().Set("x-ratelimit-remaining-tokens", "100")
+		w.Header().Set("x-ratelimit-reset-tokens", "6m0s")
+		handleImageEndpoint(w, r)
+	})
+	ctx := context.Background()
+
+	response, err := client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+	if wait := response.SuggestedWait(); wait != 0 {
+		t.Errorf("expected no wait while requests remain, got %s", wait)
+	}
+
+	remaining = "0"
+	response, err = client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	checks.NoError(t, err, "CreateImage error")
+	if wait := response.SuggestedWait(); wait != 1500*time.Millisecond {
+		t.Errorf("expected to wait for the request limit reset, got %s", wait)
+	}
+}
+
+func TestRateLimitErrorSuggestedWait(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	retryAfter := "7"
+	server.RegisterHandler("/v1/images/generations", func(w http.ResponseWriter, _ *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.Header().Set("x-ratelimit-reset-tokens", "20s")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintln(w, `{"error":{"message":"rate limited","type":"requests"}}`)
+	})
+	ctx := context.Background()
+
+	_, err := client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	var apiErr *openai.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if apiErr.SuggestedWait != 7*time.Second {
+		t.Errorf("expected Retry-After to take precedence, got %s", apiErr.SuggestedWait)
+	}
+
+	retryAfter = ""
+	_, err = client.CreateImage(ctx, openai.ImageRequest{Prompt: "Lorem ipsum"})
+	if !errors.As(err, &apiErr) || apiErr.SuggestedWait != 20*time.Second {
+		t.Errorf("expected to wait for the token limit reset, got %v", err)
+	}
+}
+
+func TestRetryImageEditWithImageProvider(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 1
+		config.RetryBackoff = time.Millisecond
+	})
+	defer teardown()
+
+	data := []byte("fake image data")
+	calls := 0
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		file, _, err := r.FormFile("image")
+		if err != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		if uploaded, _ := io.ReadAll(file); !bytes.Equal(uploaded, data) {
+			http.Error(w, "unexpected image content", http.StatusBadRequest)
+			return
+		}
+		mask, _, err := r.FormFile("mask")
+		if err != nil {
+			http.Error(w, "missing mask", http.StatusBadRequest)
+			return
+		}
+		defer mask.Close()
+		if uploaded, _ := io.ReadAll(mask); string(uploaded) != "fake mask" {
+			http.Error(w, "the mask should be rewound for every attempt", http.StatusBadRequest)
+			return
+		}
+		if calls == 1 {
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		handleEditImageEndpoint(w, r)
+	})
+
+	provided := 0
+	_, err := client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		ImageProvider: func() (io.Reader, error) {
+			provided++
+			return io.MultiReader(bytes.NewReader(data)), nil
+		},
+		Mask:   bytes.NewReader([]byte("fake mask")),
+		Prompt: "There is a turtle in the pool",
+	})
+	checks.NoError(t, err, "the edit should succeed on the retry")
+	if calls != 2 || provided != 2 {
+		t.Errorf("expected 2 attempts with a fresh image each, got %d attempts and %d images", calls, provided)
+	}
+}
+
+func TestRetryImageEditWithSeekableImage(t *testing.T) {
+	client, server, teardown := setupOpenAITestServerWithConfig(func(config *openai.ClientConfig) {
+		config.MaxRetries = 2
+		config.RetryBackoff = time.Millisecond
+	})
+	defer teardown()
+
+	data := []byte("fake image data")
+	path := filepath.Join(t.TempDir(), "image.png")
+	checks.NoError(t, os.WriteFile(path, data, 0o600), "write image file error")
+	image, err := os.Open(path)
+	checks.NoError(t, err, "open image file error")
+	defer image.Close()
+
+	calls := 0
+	server.RegisterHandler("/v1/images/edits", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			// Fail before reading the body.
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		file, _, formErr := r.FormFile("image")
+		if formErr != nil {
+			http.Error(w, "missing image", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+		if uploaded, _ := io.ReadAll(file); !bytes.Equal(uploaded, data) {
+			http.Error(w, "the image should be rewound for every attempt", http.StatusBadRequest)
+			return
+		}
+		if calls == 2 {
+			http.Error(w, `{"error":{"message":"overloaded","type":"server_error"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		handleEditImageEndpoint(w, r)
+	})
+
+	_, err = client.CreateEditImage(context.Background(), openai.ImageEditRequest{
+		Image:  image,
+		Prompt: "There is a turtle in the pool",
+	})
+	checks.NoError(t, err, "the edit should succeed on the last retry")
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+}
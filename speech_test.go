@@ -90,7 +90,7 @@ func TestSpeechIntegration(t *testing.T) {
 		checks.NoError(t, err, "ReadAll error")
 
 		// save buf to file as mp3
-		err = os.WriteFile("test.mp3", buf, 0644)
+		err = os.WriteFile(filepath.Join(t.TempDir(), "test.mp3"), buf, 0644)
 		checks.NoError(t, err, "Create error")
 	})
 }
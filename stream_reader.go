@@ -14,10 +14,13 @@ import (
 var (
 	headerData  = regexp.MustCompile(`^data:\s*`)
 	errorPrefix = regexp.MustCompile(`^data:\s*{"error":`)
+	// sseMetadata matches the event, id and retry fields and the comments of server-sent events,
+	// which carry no data and must not end up in the error accumulator.
+	sseMetadata = regexp.MustCompile(`^(event|id|retry):|^:`)
 )
 
 type streamable interface {
-	ChatCompletionStreamResponse | CompletionResponse
+	ChatCompletionStreamResponse | CompletionResponse | ImageStreamEvent
 }
 
 type streamReader[T streamable] struct {
@@ -74,6 +77,13 @@ func (stream *streamReader[T]) processLines() ([]byte, error) {
 		if errorPrefix.Match(noSpaceLine) {
 			hasErrorPrefix = true
 		}
+		if !hasErrorPrefix && sseMetadata.Match(noSpaceLine) {
+			emptyMessagesCount++
+			if emptyMessagesCount > stream.emptyMessagesLimit {
+				return nil, ErrTooManyEmptyStreamMessages
+			}
+			continue
+		}
 		if !headerData.Match(noSpaceLine) || hasErrorPrefix {
 			if hasErrorPrefix {
 				noSpaceLine = headerData.ReplaceAll(noSpaceLine, nil)